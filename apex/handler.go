@@ -1,8 +1,10 @@
 package apex_ecslogs
 
 import (
+	"context"
 	"encoding/json"
 	"io"
+	"unicode/utf8"
 
 	apex "github.com/apex/log"
 	ecslogs "github.com/segmentio/ecs-logs-go"
@@ -13,6 +15,14 @@ type Config struct {
 	Depth       int
 	FuncInfo    func(uintptr) (ecslogs.FuncInfo, bool)
 	MaxFieldLen int
+	Limits      ecslogs.Limits
+	Hooks       []ecslogs.Hook
+
+	// ExitOnLevel, ExitCode and Exit configure the exit policy of the
+	// underlying ecslogs logger.
+	ExitOnLevel ecslogs.Level
+	ExitCode    int
+	Exit        func(int)
 }
 
 func NewHandler(w io.Writer) apex.Handler {
@@ -20,7 +30,15 @@ func NewHandler(w io.Writer) apex.Handler {
 }
 
 func NewHandlerWith(c Config) apex.Handler {
-	logger := ecslogs.NewLogger(c.Output)
+	logger := ecslogs.NewLoggerWith(ecslogs.Config{
+		Output: c.Output,
+		Limits: c.Limits,
+		Hooks:  c.Hooks,
+
+		ExitOnLevel: c.ExitOnLevel,
+		ExitCode:    c.ExitCode,
+		Exit:        c.Exit,
+	})
 
 	if c.FuncInfo == nil {
 		return apex.HandlerFunc(func(entry *apex.Entry) error {
@@ -41,17 +59,21 @@ func NewHandlerWith(c Config) apex.Handler {
 	})
 }
 
+// WithContext returns an entry of logger carrying the fields set on ctx with
+// ecslogs.ContextWithFields and the identifiers of its trace.
+func WithContext(ctx context.Context, logger apex.Interface) *apex.Entry {
+	return logger.WithFields(apex.Fields(ecslogs.ContextData(ctx)))
+}
+
 func MakeEvent(entry *apex.Entry, maxFieldLen int) ecslogs.Event {
 	return makeEvent(entry, "", maxFieldLen)
 }
 
 func makeEvent(entry *apex.Entry, source string, maxFieldLen int) ecslogs.Event {
-	var message string
+	message := entry.Message
 
-	if maxFieldLen > 0 && len(entry.Message) > maxFieldLen {
-		message = entry.Message[:maxFieldLen]
-	} else {
-		message = entry.Message
+	if maxFieldLen > 0 {
+		message = truncate(message, maxFieldLen)
 	}
 
 	return ecslogs.Event{
@@ -77,11 +99,7 @@ func makeEventData(entry *apex.Entry, maxFieldLen int) ecslogs.EventData {
 		for k, v := range entry.Fields {
 			switch obj := v.(type) {
 			case string:
-				if len(obj) > maxFieldLen {
-					data[k] = obj[:maxFieldLen]
-				} else {
-					data[k] = obj
-				}
+				data[k] = truncate(obj, maxFieldLen)
 			case bool, int8, uint8, int16, uint16, int32, uint32, int64, uint64, int, uint, uintptr,
 				float32, float64:
 				data[k] = obj
@@ -103,6 +121,19 @@ func makeEventData(entry *apex.Entry, maxFieldLen int) ecslogs.EventData {
 	return data
 }
 
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
+
 func makeLevel(level apex.Level) ecslogs.Level {
 	switch level {
 	case apex.DebugLevel:
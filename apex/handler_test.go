@@ -2,9 +2,11 @@ package apex_ecslogs
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	apex "github.com/apex/log"
 	ecslogs "github.com/segmentio/ecs-logs-go"
@@ -65,6 +67,21 @@ func TestHandlerMaxFieldLength(t *testing.T) {
 	}
 }
 
+func TestMakeEventMaxFieldLenUTF8(t *testing.T) {
+	e := MakeEvent(&apex.Entry{
+		Message: "héllo",
+		Fields:  apex.Fields{"name": "dédé"},
+	}, 2)
+
+	if e.Message != "h" || !utf8.ValidString(e.Message) {
+		t.Errorf("invalid message: %q", e.Message)
+	}
+
+	if s := e.Data["name"]; s != "d" {
+		t.Errorf("invalid data: %q", s)
+	}
+}
+
 func testFuncInfo(pc uintptr) (info ecslogs.FuncInfo, ok bool) {
 	if info, ok = ecslogs.GetFuncInfo(pc); !ok {
 		return
@@ -72,3 +89,50 @@ func testFuncInfo(pc uintptr) (info ecslogs.FuncInfo, ok bool) {
 	info.Line = 42
 	return
 }
+
+func TestHandlerExit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	code := 0
+	log := &apex.Logger{
+		Handler: NewHandlerWith(Config{
+			Output:      buf,
+			ExitOnLevel: ecslogs.ERROR,
+			Exit:        func(c int) { code = c },
+		}),
+		Level: apex.DebugLevel,
+	}
+
+	log.Warn("A")
+
+	if code != 0 {
+		t.Fatal("the program exited on a WARN entry")
+	}
+
+	log.Error("B")
+
+	if code != 1 {
+		t.Error("invalid exit code:", code)
+	}
+
+	if s := strings.TrimSpace(buf.String()); !strings.HasSuffix(s, `"message":"B"}`) {
+		t.Error("apex handler failed:", s)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := &apex.Logger{
+		Handler: NewHandler(buf),
+		Level:   apex.DebugLevel,
+	}
+
+	ctx := ecslogs.ContextWithFields(context.Background(), ecslogs.EventData{"requestId": "1234"})
+	ctx = ecslogs.ContextWithTrace(ctx, ecslogs.TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736"})
+	WithContext(ctx, log).Info("hello")
+
+	s := strings.TrimSpace(buf.String())
+
+	if !strings.HasSuffix(s, `"data":{"requestId":"1234","traceId":"4bf92f3577b34da6a3ce929d0e0e4736"},"message":"hello"}`) {
+		t.Error("apex handler failed:", s)
+	}
+}
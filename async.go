@@ -0,0 +1,171 @@
+package ecslogs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrClosed = errors.New("ecslogs: logger closed")
+
+type AsyncConfig struct {
+	BufferSize int
+
+	// Events at DropLevel or less severe are dropped once the queue is filled
+	// past DropThreshold, the remaining room is kept for the other levels.
+	DropLevel     Level
+	DropThreshold float64
+}
+
+type AsyncLogger struct {
+	logger    Logger
+	queue     chan asyncItem
+	done      chan struct{}
+	dropLevel Level
+	dropMark  int
+	mutex     sync.RWMutex
+	closed    bool
+	dropped   [TRACE + 1]uint64
+}
+
+type asyncItem struct {
+	event Event
+	flush chan<- struct{}
+}
+
+func NewAsyncLogger(logger Logger, c AsyncConfig) *AsyncLogger {
+	if c.BufferSize <= 0 {
+		c.BufferSize = 1024
+	}
+
+	if c.DropLevel == NONE {
+		c.DropLevel = DEBUG
+	}
+
+	if c.DropThreshold <= 0 || c.DropThreshold > 1 {
+		c.DropThreshold = 0.75
+	}
+
+	a := &AsyncLogger{
+		logger:    logger,
+		queue:     make(chan asyncItem, c.BufferSize),
+		done:      make(chan struct{}),
+		dropLevel: c.DropLevel,
+		dropMark:  int(float64(c.BufferSize) * c.DropThreshold),
+	}
+
+	go a.run()
+	return a
+}
+
+// Log queues event to be logged in the background. The data map of the event
+// is copied so the caller can reuse it, but values nested in it must not be
+// modified after Log returns.
+func (a *AsyncLogger) Log(event Event) error {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	if a.closed {
+		return ErrClosed
+	}
+
+	if event.Level >= a.dropLevel && len(a.queue) >= a.dropMark {
+		a.drop(event.Level)
+		return nil
+	}
+
+	if event.Data != nil {
+		event.Data = copyEventData(event.Data)
+	}
+
+	select {
+	case a.queue <- asyncItem{event: event}:
+	default:
+		a.drop(event.Level)
+	}
+
+	return nil
+}
+
+func (a *AsyncLogger) Flush(ctx context.Context) error {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	if a.closed {
+		return ErrClosed
+	}
+
+	done := make(chan struct{})
+
+	select {
+	case a.queue <- asyncItem{flush: done}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if f, ok := a.logger.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+
+	return nil
+}
+
+func (a *AsyncLogger) Close() error {
+	a.mutex.Lock()
+
+	if a.closed {
+		a.mutex.Unlock()
+		return nil
+	}
+
+	a.closed = true
+	close(a.queue)
+	a.mutex.Unlock()
+	<-a.done
+
+	if f, ok := a.logger.(Flusher); ok {
+		return f.Flush(context.Background())
+	}
+
+	return nil
+}
+
+func (a *AsyncLogger) Dropped() (n uint64) {
+	for i := range a.dropped {
+		n += atomic.LoadUint64(&a.dropped[i])
+	}
+	return
+}
+
+func (a *AsyncLogger) DroppedLevel(lvl Level) uint64 {
+	if lvl < NONE || lvl > TRACE {
+		return 0
+	}
+	return atomic.LoadUint64(&a.dropped[lvl])
+}
+
+func (a *AsyncLogger) drop(lvl Level) {
+	if lvl < NONE || lvl > TRACE {
+		lvl = NONE
+	}
+	atomic.AddUint64(&a.dropped[lvl], 1)
+}
+
+func (a *AsyncLogger) run() {
+	defer close(a.done)
+
+	for item := range a.queue {
+		if item.flush != nil {
+			close(item.flush)
+			continue
+		}
+		a.logger.Log(item.event)
+	}
+}
@@ -0,0 +1,126 @@
+package ecslogs
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestAsyncLoggerFlush(t *testing.T) {
+	var events []Event
+
+	log := NewAsyncLogger(LoggerFunc(func(e Event) error {
+		events = append(events, e)
+		return nil
+	}), AsyncConfig{})
+	defer log.Close()
+
+	for i := 0; i != 10; i++ {
+		log.Log(Eprintf(INFO, "event #%d", i))
+	}
+
+	if err := log.Flush(context.Background()); err != nil {
+		t.Error(err)
+	}
+
+	if len(events) != 10 {
+		t.Errorf("invalid number of events flushed: %d", len(events))
+	}
+
+	for i, e := range events {
+		if s := Eprintf(INFO, "event #%d", i).Message; e.Message != s {
+			t.Errorf("invalid event at index %d: %s", i, e.Message)
+		}
+	}
+}
+
+func TestAsyncLoggerReuseData(t *testing.T) {
+	var events []Event
+
+	log := NewAsyncLogger(LoggerFunc(func(e Event) error {
+		events = append(events, e)
+		return nil
+	}), AsyncConfig{})
+	defer log.Close()
+
+	data := EventData{}
+
+	for i := 0; i != 10; i++ {
+		data["i"] = i
+		log.Log(Event{Level: INFO, Data: data})
+	}
+
+	if err := log.Flush(context.Background()); err != nil {
+		t.Error(err)
+	}
+
+	for i, e := range events {
+		if n := e.Data["i"]; n != i {
+			t.Errorf("invalid data at index %d: %v", i, n)
+		}
+	}
+}
+
+func TestAsyncLoggerDrop(t *testing.T) {
+	block := make(chan struct{})
+	mutex := sync.Mutex{}
+	count := map[Level]int{}
+
+	log := NewAsyncLogger(LoggerFunc(func(e Event) error {
+		<-block
+		mutex.Lock()
+		count[e.Level]++
+		mutex.Unlock()
+		return nil
+	}), AsyncConfig{
+		BufferSize:    4,
+		DropThreshold: 0.5,
+	})
+
+	// The first event is picked up by the background goroutine and stays
+	// blocked there, which gives a deterministic view of the queue.
+	log.Log(Eprint(INFO, "blocked"))
+	for len(log.queue) != 0 {
+		runtime.Gosched()
+	}
+
+	log.Log(Eprint(DEBUG, "debug #1"))
+	log.Log(Eprint(DEBUG, "debug #2"))
+	log.Log(Eprint(DEBUG, "debug #3")) // dropped, past the threshold
+	log.Log(Eprint(ERROR, "error #1"))
+	log.Log(Eprint(ERROR, "error #2"))
+	log.Log(Eprint(ERROR, "error #3")) // dropped, the queue is full
+
+	close(block)
+	log.Close()
+
+	if n := log.Dropped(); n != 2 {
+		t.Error("invalid number of dropped events:", n)
+	}
+
+	if n := log.DroppedLevel(DEBUG); n != 1 {
+		t.Error("invalid number of dropped DEBUG events:", n)
+	}
+
+	if n := log.DroppedLevel(ERROR); n != 1 {
+		t.Error("invalid number of dropped ERROR events:", n)
+	}
+
+	if count[DEBUG] != 2 || count[ERROR] != 2 || count[INFO] != 1 {
+		t.Error("invalid events logged:", count)
+	}
+}
+
+func TestAsyncLoggerClosed(t *testing.T) {
+	log := NewAsyncLogger(LoggerFunc(func(e Event) error { return nil }), AsyncConfig{})
+	log.Close()
+
+	if err := log.Log(Eprint(INFO, "")); err != ErrClosed {
+		t.Error("invalid error returned after closing the logger:", err)
+	}
+
+	if err := log.Flush(context.Background()); err != ErrClosed {
+		t.Error("invalid error returned after closing the logger:", err)
+	}
+}
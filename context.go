@@ -0,0 +1,96 @@
+package ecslogs
+
+import (
+	"context"
+	"os"
+)
+
+type contextKey int
+
+const (
+	fieldsContextKey contextKey = iota
+	loggerContextKey
+	traceContextKey
+)
+
+// ContextLogger is implemented by loggers that can extract values from a
+// context to add them to the events they log.
+type ContextLogger interface {
+	Logger
+	LogContext(context.Context, Event) error
+}
+
+// ContextWithFields returns a context carrying fields merged with the ones
+// already present in ctx, fields set later take precedence.
+func ContextWithFields(ctx context.Context, fields EventData) context.Context {
+	return context.WithValue(ctx, fieldsContextKey, copyEventData(ContextFields(ctx), fields))
+}
+
+// ContextFields returns the fields carried by ctx, the returned value must
+// not be modified.
+func ContextFields(ctx context.Context) EventData {
+	fields, _ := ctx.Value(fieldsContextKey).(EventData)
+	return fields
+}
+
+// ContextData returns the fields carried by ctx with the identifiers of the
+// trace found in it, which is what LogContext adds to events.
+func ContextData(ctx context.Context) EventData {
+	fields := ContextFields(ctx)
+
+	if trace, ok := TraceFromContext(ctx); ok {
+		fields = copyEventData(fields, trace.Data())
+	}
+
+	return fields
+}
+
+func ContextWithLogger(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, logger)
+}
+
+// LoggerFromContext returns the logger carried by ctx, or one writing to
+// stderr if there are none. Events logged with the returned logger have the
+// fields of ctx added to them.
+func LoggerFromContext(ctx context.Context) Logger {
+	logger, _ := ctx.Value(loggerContextKey).(Logger)
+
+	if logger == nil {
+		logger = NewLogger(os.Stderr)
+	}
+
+	return contextLogger{ctx: ctx, logger: logger}
+}
+
+// LogContext logs event with logger, adding the fields and trace identifiers
+// of ctx to its data. Fields already set on the event are left untouched.
+func LogContext(ctx context.Context, logger Logger, event Event) error {
+	if c, ok := logger.(ContextLogger); ok {
+		return c.LogContext(ctx, event)
+	}
+	return logger.Log(withContextFields(ctx, event))
+}
+
+func withContextFields(ctx context.Context, event Event) Event {
+	if fields := ContextData(ctx); len(fields) != 0 {
+		event.Data = copyEventData(fields, event.Data)
+	}
+	return event
+}
+
+type contextLogger struct {
+	ctx    context.Context
+	logger Logger
+}
+
+func (c contextLogger) Log(event Event) error {
+	return LogContext(c.ctx, c.logger, event)
+}
+
+func (c contextLogger) Flush(ctx context.Context) error {
+	return flushLogger(ctx, c.logger)
+}
+
+func (c contextLogger) LogContext(ctx context.Context, event Event) error {
+	return LogContext(ctx, c.logger, withContextFields(c.ctx, event))
+}
@@ -0,0 +1,58 @@
+package ecslogs
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestContextFields(t *testing.T) {
+	ctx := context.Background()
+	ctx = ContextWithFields(ctx, EventData{"requestId": "1234", "user": "A"})
+	ctx = ContextWithFields(ctx, EventData{"user": "B"})
+
+	if s := ContextFields(ctx).String(); s != `{"requestId":"1234","user":"B"}` {
+		t.Error("invalid context fields:", s)
+	}
+
+	if f := ContextFields(context.Background()); f != nil {
+		t.Error("unexpected fields found in an empty context:", f)
+	}
+}
+
+func TestLogContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := ContextWithFields(context.Background(), EventData{"requestId": "1234", "user": "A"})
+
+	e := Eprint(INFO, "hello")
+	e.Data["user"] = "B"
+
+	if err := LogContext(ctx, NewLogger(buf), e); err != nil {
+		t.Error(err)
+	}
+
+	const expected = `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{},"data":{"requestId":"1234","user":"B"},"message":"hello"}`
+
+	if s := strings.TrimSpace(buf.String()); s != expected {
+		t.Errorf("\n- expected: %s\n- found:    %s", expected, s)
+	}
+
+	if len(e.Data) != 1 {
+		t.Error("the original event data was modified:", e.Data)
+	}
+}
+
+func TestLoggerFromContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := ContextWithLogger(context.Background(), NewLogger(buf))
+	ctx = ContextWithFields(ctx, EventData{"requestId": "1234"})
+
+	LoggerFromContext(ctx).Log(Eprint(INFO, "hello"))
+
+	const expected = `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{},"data":{"requestId":"1234"},"message":"hello"}`
+
+	if s := strings.TrimSpace(buf.String()); s != expected {
+		t.Errorf("\n- expected: %s\n- found:    %s", expected, s)
+	}
+}
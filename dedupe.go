@@ -0,0 +1,191 @@
+package ecslogs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type DedupeConfig struct {
+	// Window is how long after the last occurrence an identical event is still
+	// considered a repeat.
+	Window time.Duration
+
+	// Key returns the value used to compare events, defaults to DedupeKey().
+	Key func(Event) string
+
+	Now func() time.Time
+}
+
+// DedupeKey returns a key function comparing the level, message and data of
+// events, leaving out the data fields named in ignore.
+func DedupeKey(ignore ...string) func(Event) string {
+	return func(e Event) string {
+		data := e.Data
+
+		if len(ignore) != 0 {
+			data = copyEventData(data)
+			for _, k := range ignore {
+				delete(data, k)
+			}
+		}
+
+		return e.Level.String() + "\x00" + e.Message + "\x00" + data.String()
+	}
+}
+
+// Deduper suppresses events that are repeated in a row, when a different
+// event is logged or the window has expired a copy of the original event is
+// emitted with the number of repeats and when they happened. The summary is
+// emitted when the window expires even if no other events are logged.
+type Deduper struct {
+	logger Logger
+	window time.Duration
+	key    func(Event) string
+	now    func() time.Time
+
+	mutex sync.Mutex
+	last  string
+	event Event
+	count int
+	first time.Time
+	seen  time.Time
+	until time.Time
+	timer *time.Timer
+}
+
+func NewDeduper(logger Logger, c DedupeConfig) *Deduper {
+	if c.Window <= 0 {
+		c.Window = 10 * time.Second
+	}
+
+	if c.Key == nil {
+		c.Key = DedupeKey()
+	}
+
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+
+	return &Deduper{
+		logger: logger,
+		window: c.Window,
+		key:    c.Key,
+		now:    c.Now,
+	}
+}
+
+func (d *Deduper) Log(event Event) error {
+	key := d.key(event)
+	now := d.now()
+	eventTime := event.Time
+
+	if eventTime.IsZero() {
+		eventTime = now
+	}
+
+	d.mutex.Lock()
+
+	if len(d.last) != 0 && key == d.last && now.Before(d.until) {
+		d.count++
+		d.seen = eventTime
+		d.until = now.Add(d.window)
+
+		if d.timer == nil {
+			d.timer = d.schedule(d.window)
+		}
+
+		d.mutex.Unlock()
+		return nil
+	}
+
+	summary, hasSummary := d.summary()
+	d.last, d.event, d.count = key, event, 0
+	d.first, d.seen, d.until = eventTime, eventTime, now.Add(d.window)
+	d.mutex.Unlock()
+
+	var errs MultiError
+
+	if hasSummary {
+		if err := d.logger.Log(summary); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := d.logger.Log(event); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs.err()
+}
+
+func (d *Deduper) Flush(ctx context.Context) error {
+	d.mutex.Lock()
+	summary, hasSummary := d.summary()
+	d.last, d.event, d.count = "", Event{}, 0
+	d.mutex.Unlock()
+
+	if hasSummary {
+		if err := d.logger.Log(summary); err != nil {
+			return err
+		}
+	}
+
+	if f, ok := d.logger.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+
+	return nil
+}
+
+// schedule returns a timer emitting the summary of repeats once the window
+// has expired, it is pushed back while the event keeps being repeated.
+func (d *Deduper) schedule(after time.Duration) *time.Timer {
+	var timer *time.Timer
+
+	timer = time.AfterFunc(after, func() {
+		d.mutex.Lock()
+
+		if d.timer != timer {
+			d.mutex.Unlock()
+			return
+		}
+
+		if remain := d.until.Sub(d.now()); remain > 0 {
+			timer.Reset(remain)
+			d.mutex.Unlock()
+			return
+		}
+
+		summary, hasSummary := d.summary()
+		d.last, d.event, d.count = "", Event{}, 0
+		d.mutex.Unlock()
+
+		if hasSummary {
+			d.logger.Log(summary)
+		}
+	})
+
+	return timer
+}
+
+func (d *Deduper) summary() (event Event, ok bool) {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+
+	if d.count == 0 {
+		return
+	}
+
+	event = d.event
+	event.Time = d.seen
+	event.Data = copyEventData(event.Data, EventData{
+		"repeated":  d.count,
+		"firstTime": d.first,
+		"lastTime":  d.seen,
+	})
+	ok = true
+	return
+}
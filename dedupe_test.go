@@ -0,0 +1,117 @@
+package ecslogs
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDeduper(t *testing.T) {
+	var events []Event
+
+	now := time.Date(2016, 12, 5, 10, 31, 42, 0, time.UTC)
+	start := now
+	log := NewDeduper(LoggerFunc(func(e Event) error {
+		events = append(events, e)
+		return nil
+	}), DedupeConfig{
+		Window: time.Second,
+		Key:    DedupeKey("requestId"),
+		Now:    func() time.Time { return now },
+	})
+
+	for i := 0; i != 5; i++ {
+		e := Eprint(ERROR, "connection refused")
+		e.Info.Source = "main.go:42:main"
+		e.Data["requestId"] = i
+		log.Log(e)
+		now = now.Add(500 * time.Millisecond)
+	}
+
+	log.Log(Eprint(INFO, "connected"))
+
+	if len(events) != 3 {
+		t.Fatal("invalid number of events logged:", len(events))
+	}
+
+	summary := events[1]
+
+	if summary.Level != ERROR || summary.Message != "connection refused" || summary.Info.Source != "main.go:42:main" {
+		t.Error("invalid summary event:", summary)
+	}
+
+	if n := summary.Data["repeated"]; n != 4 {
+		t.Error("invalid repeat count:", n)
+	}
+
+	if first := summary.Data["firstTime"]; first != start {
+		t.Error("invalid first time:", first)
+	}
+
+	if last := summary.Data["lastTime"]; last != start.Add(2*time.Second) {
+		t.Error("invalid last time:", last)
+	}
+
+	if events[2].Message != "connected" {
+		t.Error("invalid event logged after the summary:", events[2])
+	}
+}
+
+func TestDeduperWindow(t *testing.T) {
+	var events []Event
+
+	now := time.Date(2016, 12, 5, 10, 31, 42, 0, time.UTC)
+	log := NewDeduper(LoggerFunc(func(e Event) error {
+		events = append(events, e)
+		return nil
+	}), DedupeConfig{
+		Window: time.Second,
+		Now:    func() time.Time { return now },
+	})
+
+	log.Log(Eprint(WARN, "A"))
+	now = now.Add(2 * time.Second)
+	log.Log(Eprint(WARN, "A"))
+	log.Log(Eprint(WARN, "A"))
+	log.Flush(context.Background())
+
+	if len(events) != 3 {
+		t.Fatal("invalid number of events logged:", len(events))
+	}
+
+	if _, ok := events[1].Data["repeated"]; ok {
+		t.Error("an event logged after the window expired was considered a repeat")
+	}
+
+	if n := events[2].Data["repeated"]; n != 1 {
+		t.Error("invalid repeat count after flushing:", n)
+	}
+}
+
+func TestDeduperTimer(t *testing.T) {
+	events := make(chan Event, 10)
+
+	log := NewDeduper(LoggerFunc(func(e Event) error {
+		events <- e
+		return nil
+	}), DedupeConfig{
+		Window: 10 * time.Millisecond,
+	})
+
+	log.Log(Eprint(WARN, "A"))
+	log.Log(Eprint(WARN, "A"))
+	log.Log(Eprint(WARN, "A"))
+
+	if e := <-events; e.Message != "A" {
+		t.Error("invalid event logged:", e)
+	}
+
+	select {
+	case e := <-events:
+		if n := e.Data["repeated"]; n != 2 {
+			t.Error("invalid repeat count:", n)
+		}
+	case <-time.After(time.Second):
+		t.Error("no summary emitted after the window has expired")
+	}
+}
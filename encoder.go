@@ -0,0 +1,596 @@
+package ecslogs
+
+import (
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// Encoder writes events in the ecs-logs format, the output is identical to
+// what a json.Encoder would produce but the known parts of the events are
+// encoded without going through reflection.
+type Encoder struct {
+	w      io.Writer
+	flags  json.AppendFlags
+	limits Limits
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w:     w,
+		flags: json.EscapeHTML | json.SortMapKeys,
+	}
+}
+
+func (enc *Encoder) SetEscapeHTML(on bool) {
+	if on {
+		enc.flags |= json.EscapeHTML
+	} else {
+		enc.flags &^= json.EscapeHTML
+	}
+}
+
+func (enc *Encoder) SetLimits(limits Limits) {
+	enc.limits = limits
+}
+
+func (enc *Encoder) Encode(event Event) (err error) {
+	buf := encodeBufferPool.Get().(*encodeBuffer)
+
+	if enc.limits.enabled() {
+		buf.b, err = appendEventLimits(buf.b[:0], event, enc.flags, enc.limits)
+	} else {
+		buf.b, err = appendEvent(buf.b[:0], event, enc.flags)
+	}
+
+	if err == nil {
+		buf.b = append(buf.b, '\n')
+		_, err = enc.w.Write(buf.b)
+	}
+
+	if cap(buf.b) <= maxPooledBufferSize {
+		encodeBufferPool.Put(buf)
+	}
+
+	return
+}
+
+func (enc *Encoder) encodeBound(event Event, bound []boundField) (err error) {
+	buf := encodeBufferPool.Get().(*encodeBuffer)
+
+	if buf.b, err = appendEventBound(buf.b[:0], event, enc.flags, bound); err == nil {
+		buf.b = append(buf.b, '\n')
+		_, err = enc.w.Write(buf.b)
+	}
+
+	if cap(buf.b) <= maxPooledBufferSize {
+		encodeBufferPool.Put(buf)
+	}
+
+	return
+}
+
+func AppendEvent(b []byte, event Event) ([]byte, error) {
+	return appendEvent(b, event, json.EscapeHTML|json.SortMapKeys)
+}
+
+const maxPooledBufferSize = 64 * 1024
+
+type encodeBuffer struct {
+	b []byte
+}
+
+var encodeBufferPool = sync.Pool{
+	New: func() interface{} { return &encodeBuffer{b: make([]byte, 0, 1024)} },
+}
+
+func appendEvent(b []byte, e Event, flags json.AppendFlags) ([]byte, error) {
+	return appendEventBound(b, e, flags, nil)
+}
+
+// appendEventBound encodes e with the pre-encoded fields of bound merged in
+// its data, as if they had been copied with copyEventData(bound, e.Data).
+func appendEventBound(b []byte, e Event, flags json.AppendFlags, bound []boundField) (_ []byte, err error) {
+	b = append(b, `{"level":`...)
+	b = appendString(b, e.Level.String(), flags)
+	b = append(b, `,"time":"`...)
+	b = e.Time.AppendFormat(b, time.RFC3339Nano)
+	b = append(b, `","info":`...)
+
+	if b, err = appendEventInfo(b, e.Info, flags); err != nil {
+		return b, err
+	}
+
+	b = append(b, `,"data":`...)
+
+	if bound != nil {
+		b, err = appendBoundData(b, e.Data, bound, flags)
+	} else {
+		b, err = appendEventData(b, e.Data, flags)
+	}
+
+	if err != nil {
+		return b, err
+	}
+
+	b = append(b, `,"message":`...)
+	b = appendString(b, e.Message, flags)
+	b = append(b, '}')
+	return b, nil
+}
+
+func appendEventLimits(b []byte, e Event, flags json.AppendFlags, limits Limits) (_ []byte, err error) {
+	start := len(b)
+	e = limits.apply(e)
+
+	if b, err = appendEvent(b, e, flags); err == nil && limits.MaxEventSize > 0 && len(b)-start > limits.MaxEventSize {
+		b, err = limits.shrink(b[:start], e, flags)
+	}
+
+	return b, err
+}
+
+func appendEventInfo(b []byte, info EventInfo, flags json.AppendFlags) (_ []byte, err error) {
+	b = append(b, '{')
+	i := len(b)
+
+	if len(info.Host) != 0 {
+		b = appendKey(b, i, "host")
+		b = appendString(b, info.Host, flags)
+	}
+
+	if len(info.Source) != 0 {
+		b = appendKey(b, i, "source")
+		b = appendString(b, info.Source, flags)
+	}
+
+	if len(info.ID) != 0 {
+		b = appendKey(b, i, "id")
+		b = appendString(b, info.ID, flags)
+	}
+
+	if info.PID != 0 {
+		b = appendKey(b, i, "pid")
+		b = strconv.AppendInt(b, int64(info.PID), 10)
+	}
+
+	if info.UID != 0 {
+		b = appendKey(b, i, "uid")
+		b = strconv.AppendInt(b, int64(info.UID), 10)
+	}
+
+	if info.GID != 0 {
+		b = appendKey(b, i, "gid")
+		b = strconv.AppendInt(b, int64(info.GID), 10)
+	}
+
+	if len(info.Errors) != 0 {
+		b = appendKey(b, i, "errors")
+		b = append(b, '[')
+
+		for j, e := range info.Errors {
+			if j != 0 {
+				b = append(b, ',')
+			}
+			if b, err = appendEventError(b, e, flags); err != nil {
+				return b, err
+			}
+		}
+
+		b = append(b, ']')
+	}
+
+	if len(info.Truncated) != 0 {
+		b = appendKey(b, i, "truncated")
+		b = appendStrings(b, info.Truncated, flags)
+	}
+
+	if len(info.Dropped) != 0 {
+		b = appendKey(b, i, "dropped")
+		b = appendStrings(b, info.Dropped, flags)
+	}
+
+	b = append(b, '}')
+	return b, nil
+}
+
+func appendStrings(b []byte, s []string, flags json.AppendFlags) []byte {
+	b = append(b, '[')
+
+	for i, v := range s {
+		if i != 0 {
+			b = append(b, ',')
+		}
+		b = appendString(b, v, flags)
+	}
+
+	return append(b, ']')
+}
+
+func appendEventError(b []byte, e EventError, flags json.AppendFlags) (_ []byte, err error) {
+	b = append(b, '{')
+	i := len(b)
+
+	if len(e.Type) != 0 {
+		b = appendKey(b, i, "type")
+		b = appendString(b, e.Type, flags)
+	}
+
+	if len(e.Error) != 0 {
+		b = appendKey(b, i, "error")
+		b = appendString(b, e.Error, flags)
+	}
+
+	if e.Errno != 0 {
+		b = appendKey(b, i, "errno")
+		b = strconv.AppendInt(b, int64(e.Errno), 10)
+	}
+
+	if len(e.Op) != 0 {
+		b = appendKey(b, i, "op")
+		b = appendString(b, e.Op, flags)
+	}
+
+	if len(e.Path) != 0 {
+		b = appendKey(b, i, "path")
+		b = appendString(b, e.Path, flags)
+	}
+
+	if e.Stack != nil {
+		b = appendKey(b, i, "stack")
+		if b, err = json.Append(b, e.Stack, flags); err != nil {
+			return b, err
+		}
+	}
+
+	if e.OriginalError != nil {
+		b = appendKey(b, i, "origError")
+		if b, err = json.Append(b, e.OriginalError, flags); err != nil {
+			return b, err
+		}
+	}
+
+	if len(e.Causes) != 0 {
+		b = appendKey(b, i, "causes")
+		b = append(b, '[')
+
+		for j, c := range e.Causes {
+			if j != 0 {
+				b = append(b, ',')
+			}
+			b = appendErrorCause(b, c, flags)
+		}
+
+		b = append(b, ']')
+	}
+
+	b = append(b, '}')
+	return b, nil
+}
+
+func appendErrorCause(b []byte, c ErrorCause, flags json.AppendFlags) []byte {
+	b = append(b, '{')
+	i := len(b)
+
+	if len(c.Type) != 0 {
+		b = appendKey(b, i, "type")
+		b = appendString(b, c.Type, flags)
+	}
+
+	if len(c.Error) != 0 {
+		b = appendKey(b, i, "error")
+		b = appendString(b, c.Error, flags)
+	}
+
+	if c.Errno != 0 {
+		b = appendKey(b, i, "errno")
+		b = strconv.AppendInt(b, int64(c.Errno), 10)
+	}
+
+	if c.Depth != 0 {
+		b = appendKey(b, i, "depth")
+		b = strconv.AppendInt(b, int64(c.Depth), 10)
+	}
+
+	return append(b, '}')
+}
+
+func appendEventData(b []byte, data EventData, flags json.AppendFlags) ([]byte, error) {
+	return appendMap(b, data, flags)
+}
+
+func appendMap(b []byte, m map[string]interface{}, flags json.AppendFlags) (_ []byte, err error) {
+	if m == nil {
+		return append(b, "null"...), nil
+	}
+
+	var array [16]string
+	var keys = array[:0]
+	var start = len(b)
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sortKeys(keys)
+	b = append(b, '{')
+
+	for i, k := range keys {
+		if i != 0 {
+			b = append(b, ',')
+		}
+
+		b = appendString(b, k, flags)
+		b = append(b, ':')
+
+		if b, err = appendValue(b, m[k], flags); err != nil {
+			return b[:start], err
+		}
+	}
+
+	b = append(b, '}')
+	return b, nil
+}
+
+func appendBoundData(b []byte, data EventData, bound []boundField, flags json.AppendFlags) (_ []byte, err error) {
+	var array [16]string
+	var keys = array[:0]
+	var start = len(b)
+
+	for k := range data {
+		keys = append(keys, k)
+	}
+
+	sortKeys(keys)
+	b = append(b, '{')
+
+	for i, j := 0, 0; i < len(keys) || j < len(bound); {
+		if len(b) != start+1 {
+			b = append(b, ',')
+		}
+
+		if j < len(bound) && (i == len(keys) || bound[j].key < keys[i]) {
+			b = append(b, bound[j].raw...)
+			j++
+			continue
+		}
+
+		if j < len(bound) && bound[j].key == keys[i] {
+			j++
+		}
+
+		k := keys[i]
+		i++
+		b = appendString(b, k, flags)
+		b = append(b, ':')
+
+		if b, err = appendValue(b, data[k], flags); err != nil {
+			return b[:start], err
+		}
+	}
+
+	b = append(b, '}')
+	return b, nil
+}
+
+func appendSlice(b []byte, s []interface{}, flags json.AppendFlags) (_ []byte, err error) {
+	if s == nil {
+		return append(b, "null"...), nil
+	}
+
+	start := len(b)
+	b = append(b, '[')
+
+	for i, v := range s {
+		if i != 0 {
+			b = append(b, ',')
+		}
+		if b, err = appendValue(b, v, flags); err != nil {
+			return b[:start], err
+		}
+	}
+
+	b = append(b, ']')
+	return b, nil
+}
+
+func appendValue(b []byte, v interface{}, flags json.AppendFlags) ([]byte, error) {
+	switch x := v.(type) {
+	case nil:
+		return append(b, "null"...), nil
+	case string:
+		return appendString(b, x, flags), nil
+	case bool:
+		return strconv.AppendBool(b, x), nil
+	case int:
+		return strconv.AppendInt(b, int64(x), 10), nil
+	case int8:
+		return strconv.AppendInt(b, int64(x), 10), nil
+	case int16:
+		return strconv.AppendInt(b, int64(x), 10), nil
+	case int32:
+		return strconv.AppendInt(b, int64(x), 10), nil
+	case int64:
+		return strconv.AppendInt(b, x, 10), nil
+	case uint:
+		return strconv.AppendUint(b, uint64(x), 10), nil
+	case uint8:
+		return strconv.AppendUint(b, uint64(x), 10), nil
+	case uint16:
+		return strconv.AppendUint(b, uint64(x), 10), nil
+	case uint32:
+		return strconv.AppendUint(b, uint64(x), 10), nil
+	case uint64:
+		return strconv.AppendUint(b, x, 10), nil
+	case float32:
+		return appendFloat(b, float64(x), 32, flags)
+	case float64:
+		return appendFloat(b, x, 64, flags)
+	case time.Time:
+		b = append(b, '"')
+		b = x.AppendFormat(b, time.RFC3339Nano)
+		return append(b, '"'), nil
+	case EventData:
+		return appendMap(b, x, flags)
+	case map[string]interface{}:
+		return appendMap(b, x, flags)
+	case []interface{}:
+		return appendSlice(b, x, flags)
+	default:
+		return json.Append(b, v, flags)
+	}
+}
+
+func appendFloat(b []byte, f float64, bits int, flags json.AppendFlags) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		// Let the json package report the error so it has the same type as if
+		// the value had been encoded with reflection.
+		if bits == 32 {
+			return json.Append(b, float32(f), flags)
+		}
+		return json.Append(b, f, flags)
+	}
+
+	abs := math.Abs(f)
+	fmt := byte('f')
+
+	if abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			fmt = 'e'
+		}
+	}
+
+	b = strconv.AppendFloat(b, f, fmt, -1, bits)
+
+	if fmt == 'e' {
+		// clean up e-09 to e-9
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+
+	return b, nil
+}
+
+func appendKey(b []byte, start int, key string) []byte {
+	if len(b) != start {
+		b = append(b, ',')
+	}
+	b = append(b, '"')
+	b = append(b, key...)
+	b = append(b, '"', ':')
+	return b
+}
+
+const hex = "0123456789abcdef"
+
+func appendString(b []byte, s string, flags json.AppendFlags) []byte {
+	escapeHTML := (flags & json.EscapeHTML) != 0
+	i := 0
+	j := 0
+
+	b = append(b, '"')
+
+	for j < len(s) {
+		c := s[j]
+
+		if c >= 0x20 && c <= 0x7f && c != '\\' && c != '"' && (!escapeHTML || (c != '<' && c != '>' && c != '&')) {
+			j++
+			continue
+		}
+
+		switch c {
+		case '\\', '"':
+			b = append(b, s[i:j]...)
+			b = append(b, '\\', c)
+			j++
+			i = j
+			continue
+
+		case '\n':
+			b = append(b, s[i:j]...)
+			b = append(b, '\\', 'n')
+			j++
+			i = j
+			continue
+
+		case '\r':
+			b = append(b, s[i:j]...)
+			b = append(b, '\\', 'r')
+			j++
+			i = j
+			continue
+
+		case '\t':
+			b = append(b, s[i:j]...)
+			b = append(b, '\\', 't')
+			j++
+			i = j
+			continue
+
+		case '<', '>', '&':
+			b = append(b, s[i:j]...)
+			b = append(b, `\u00`...)
+			b = append(b, hex[c>>4], hex[c&0xF])
+			j++
+			i = j
+			continue
+		}
+
+		if c < 0x20 {
+			b = append(b, s[i:j]...)
+			b = append(b, `\u00`...)
+			b = append(b, hex[c>>4], hex[c&0xF])
+			j++
+			i = j
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[j:])
+
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[i:j]...)
+			b = append(b, `\ufffd`...)
+			j += size
+			i = j
+			continue
+		}
+
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[i:j]...)
+			b = append(b, `\u202`...)
+			b = append(b, hex[r&0xF])
+			j += size
+			i = j
+			continue
+		}
+
+		j += size
+	}
+
+	b = append(b, s[i:]...)
+	b = append(b, '"')
+	return b
+}
+
+func sortKeys(keys []string) {
+	if len(keys) > 16 {
+		sort.Strings(keys)
+		return
+	}
+
+	// Insertion sort is faster on the small maps we usually see in events and
+	// doesn't force the keys to escape to the heap.
+	for i := 1; i < len(keys); i++ {
+		for j := i; j > 0 && keys[j] < keys[j-1]; j-- {
+			keys[j], keys[j-1] = keys[j-1], keys[j]
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package ecslogs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+)
+
+var encoderTests = []Event{
+	Eprintf(INFO, "answer = %d", 42),
+	Eprintf(WARN, "an error was raised (%s)", syscall.Errno(2)),
+	Eprint(ERROR, "an error was raised:", io.EOF),
+	{
+		Level:   NOTICE,
+		Time:    time.Date(2016, 12, 5, 10, 31, 42, 123456789, time.UTC),
+		Message: "<html> & \"quotes\" \\ \n\r\t\x01\x7f \u2028\u2029 \xff \u00e9",
+	},
+	{
+		Level: DEBUG,
+		Time:  time.Date(2016, 12, 5, 10, 31, 42, 0, time.FixedZone("PST", -8*3600)),
+		Info: EventInfo{
+			Host:   "localhost",
+			Source: "main.go:42:main",
+			ID:     "1234",
+			PID:    1,
+			UID:    2,
+			GID:    3,
+			Errors: []EventError{
+				{Type: "A", Error: "B", Stack: []string{"C", "D"}},
+				MakeEventError(errors.New("oops")),
+				MakeEventError(fmt.Errorf("wrapped: %w", &os.PathError{Op: "open", Path: "<file>", Err: syscall.ENOENT})),
+			},
+			Truncated: []string{"message"},
+			Dropped:   []string{"data.<a>"},
+		},
+		Data: EventData{
+			"string":  "<hello>",
+			"bool":    true,
+			"int":     -42,
+			"int8":    int8(-8),
+			"int16":   int16(-16),
+			"int32":   int32(-32),
+			"int64":   int64(-64),
+			"uint":    uint(42),
+			"uint8":   uint8(8),
+			"uint16":  uint16(16),
+			"uint32":  uint32(32),
+			"uint64":  uint64(64),
+			"uintptr": uintptr(1),
+			"float32": float32(0.1),
+			"float64": 1e-7,
+			"big":     1e21,
+			"zero":    0.0,
+			"nil":     nil,
+			"time":    time.Date(2016, 12, 5, 10, 31, 42, 0, time.UTC),
+			"data":    EventData{"b": 1, "a": []interface{}{1, "2", nil}},
+			"map":     map[string]interface{}{"z": "z", "y": map[string]string{"x": "x"}},
+			"slice":   []int{1, 2, 3},
+			"struct":  struct{ A int }{42},
+			"level":   WARN,
+			"a":       1, "b": 2, "c": 3, "d": 4, "e": 5, "f": 6, "g": 7,
+		},
+		Message: "all types",
+	},
+	{
+		Level: TRACE,
+		Data:  nil,
+	},
+	{
+		Level: Level(42),
+		Data:  EventData{"nested": []interface{}{EventData{}, []interface{}{}}},
+	},
+}
+
+func TestEncoder(t *testing.T) {
+	for _, escapeHTML := range []bool{true, false} {
+		for i, e := range encoderTests {
+			b1 := &bytes.Buffer{}
+			b2 := &bytes.Buffer{}
+
+			enc1 := json.NewEncoder(b1)
+			enc1.SetEscapeHTML(escapeHTML)
+
+			enc2 := NewEncoder(b2)
+			enc2.SetEscapeHTML(escapeHTML)
+
+			if err := enc1.Encode(e); err != nil {
+				t.Errorf("test#%d: json: %s", i, err)
+			}
+
+			if err := enc2.Encode(e); err != nil {
+				t.Errorf("test#%d: ecslogs: %s", i, err)
+			}
+
+			if s1, s2 := b1.String(), b2.String(); s1 != s2 {
+				t.Errorf("test#%d: escapeHTML=%t\n- expected: %s\n- found:    %s", i, escapeHTML, s1, s2)
+			}
+		}
+	}
+}
+
+func TestEncoderError(t *testing.T) {
+	tests := []interface{}{
+		math.NaN(),
+		float32(math.Inf(1)),
+		make(chan int),
+		EventData{"a": []interface{}{func() {}}},
+	}
+
+	for _, v := range tests {
+		e := Eprint(INFO, "")
+		e.Data["value"] = v
+
+		_, err1 := json.Marshal(e)
+		_, err2 := AppendEvent(nil, e)
+
+		if err1 == nil || err2 == nil {
+			t.Errorf("%#v: expected errors but got %v and %v", v, err1, err2)
+		} else if s1, s2 := err1.Error(), err2.Error(); s1 != s2 {
+			t.Errorf("%#v: errors mismatch:\n- expected: %s\n- found:    %s", v, s1, s2)
+		}
+	}
+}
+
+func TestEncoderAllocs(t *testing.T) {
+	e := Eprintf(INFO, "answer = %d", 42)
+	e.Data["hello"] = "world"
+	e.Data["count"] = 42
+
+	enc := NewEncoder(io.Discard)
+	enc.Encode(e)
+
+	if n := testing.AllocsPerRun(100, func() { enc.Encode(e) }); n != 0 {
+		t.Error("encoding an event caused memory allocations:", n)
+	}
+}
+
+func BenchmarkEncoder(b *testing.B) {
+	e := encoderTests[4]
+	e.Data = EventData{"hello": "world", "answer": 42, "pi": 3.14, "ok": true}
+	enc := NewEncoder(io.Discard)
+
+	for i := 0; i != b.N; i++ {
+		enc.Encode(e)
+	}
+}
+
+func BenchmarkJSONEncoder(b *testing.B) {
+	e := encoderTests[4]
+	e.Data = EventData{"hello": "world", "answer": 42, "pi": 3.14, "ok": true}
+	enc := json.NewEncoder(io.Discard)
+
+	for i := 0; i != b.N; i++ {
+		enc.Encode(e)
+	}
+}
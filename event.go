@@ -1,8 +1,14 @@
 package ecslogs
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"os"
 	"reflect"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -10,11 +16,24 @@ import (
 )
 
 type EventError struct {
-	Type          string      `json:"type,omitempty"`
-	Error         string      `json:"error,omitempty"`
-	Errno         int         `json:"errno,omitempty"`
-	Stack         interface{} `json:"stack,omitempty"`
-	OriginalError error       `json:"origError,omitempty"`
+	Type          string       `json:"type,omitempty"`
+	Error         string       `json:"error,omitempty"`
+	Errno         int          `json:"errno,omitempty"`
+	Op            string       `json:"op,omitempty"`
+	Path          string       `json:"path,omitempty"`
+	Stack         interface{}  `json:"stack,omitempty"`
+	OriginalError error        `json:"origError,omitempty"`
+	Causes        []ErrorCause `json:"causes,omitempty"`
+}
+
+// ErrorCause describes one of the errors wrapped by an EventError. Causes are
+// listed depth first, Depth is 1 for the errors wrapped directly and the
+// parent of a cause is the closest one before it with a lower depth.
+type ErrorCause struct {
+	Type  string `json:"type,omitempty"`
+	Error string `json:"error,omitempty"`
+	Errno int    `json:"errno,omitempty"`
+	Depth int    `json:"depth,omitempty"`
 }
 
 func MakeEventError(err error) EventError {
@@ -24,13 +43,106 @@ func MakeEventError(err error) EventError {
 		OriginalError: err,
 	}
 
-	if errno, ok := err.(syscall.Errno); ok {
-		e.Errno = int(errno)
+	walkErrors(err, func(cause error, depth int) {
+		e.addCause(cause, depth)
+	})
+
+	if stack := StackTrace(err); len(stack) != 0 {
+		e.Stack = stack
+	}
+
+	return e
+}
+
+// MakeEventErrorContext is like MakeEventError but also records the cause
+// of the context cancellation when err doesn't already wrap it.
+func MakeEventErrorContext(ctx context.Context, err error) EventError {
+	e := MakeEventError(err)
+
+	if cause := context.Cause(ctx); cause != nil && cause != ctx.Err() && !errors.Is(err, cause) {
+		walkErrors(cause, func(cause error, depth int) {
+			e.addCause(cause, depth+1)
+		})
 	}
 
 	return e
 }
 
+func (e *EventError) addCause(err error, depth int) {
+	var errno int
+
+	switch x := err.(type) {
+	case syscall.Errno:
+		errno = int(x)
+	case *os.PathError:
+		e.setOp(x.Op, x.Path)
+	case *os.LinkError:
+		e.setOp(x.Op, x.Old)
+	case *os.SyscallError:
+		e.setOp(x.Syscall, "")
+	}
+
+	if errno != 0 && e.Errno == 0 {
+		e.Errno = errno
+	}
+
+	if depth != 0 {
+		e.Causes = append(e.Causes, ErrorCause{
+			Type:  reflect.TypeOf(err).String(),
+			Error: err.Error(),
+			Errno: errno,
+			Depth: depth,
+		})
+	}
+}
+
+func (e *EventError) setOp(op string, path string) {
+	if len(e.Op) == 0 {
+		e.Op, e.Path = op, path
+	}
+}
+
+const maxErrorDepth = 32
+
+// walkErrors calls fn for err and every error it wraps, depth first. Both
+// the standard Unwrap methods and the Cause method of github.com/pkg/errors
+// are supported.
+func walkErrors(err error, fn func(error, int)) {
+	var walk func(error, int)
+
+	walk = func(err error, depth int) {
+		if err == nil || depth > maxErrorDepth {
+			return
+		}
+
+		fn(err, depth)
+
+		switch x := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, cause := range x.Unwrap() {
+				walk(cause, depth+1)
+			}
+		case interface{ Unwrap() error }:
+			walk(x.Unwrap(), depth+1)
+		case interface{ Cause() error }:
+			walk(x.Cause(), depth+1)
+		}
+	}
+
+	walk(err, 0)
+}
+
+func (e *EventError) UnmarshalJSON(b []byte) error {
+	// The original error cannot be reconstructed from its serialized form, the
+	// field is shadowed so it gets discarded instead of failing the decoding.
+	type eventError EventError
+	v := struct {
+		*eventError
+		OriginalError json.RawMessage `json:"origError,omitempty"`
+	}{eventError: (*eventError)(e)}
+	return json.Unmarshal(b, &v)
+}
+
 type EventInfo struct {
 	Host   string       `json:"host,omitempty"`
 	Source string       `json:"source,omitempty"`
@@ -39,6 +151,9 @@ type EventInfo struct {
 	UID    int          `json:"uid,omitempty"`
 	GID    int          `json:"gid,omitempty"`
 	Errors []EventError `json:"errors,omitempty"`
+
+	Truncated []string `json:"truncated,omitempty"`
+	Dropped   []string `json:"dropped,omitempty"`
 }
 
 func (e EventInfo) Bytes() []byte {
@@ -61,6 +176,52 @@ func (e EventData) String() string {
 	return string(e.Bytes())
 }
 
+func (e EventData) Get(path string) (interface{}, bool) {
+	return lookup(map[string]interface{}(e), path)
+}
+
+func (e EventData) GetString(path string) (s string, ok bool) {
+	var v interface{}
+	if v, ok = e.Get(path); ok {
+		s, ok = v.(string)
+	}
+	return
+}
+
+func (e EventData) GetBool(path string) (b bool, ok bool) {
+	var v interface{}
+	if v, ok = e.Get(path); ok {
+		b, ok = v.(bool)
+	}
+	return
+}
+
+func (e EventData) GetInt(path string) (i int64, ok bool) {
+	var v interface{}
+	if v, ok = e.Get(path); ok {
+		i, ok = toInt(v)
+	}
+	return
+}
+
+func (e EventData) GetFloat(path string) (f float64, ok bool) {
+	var v interface{}
+	if v, ok = e.Get(path); ok {
+		f, ok = toFloat(v)
+	}
+	return
+}
+
+func (e EventData) GetData(path string) (d EventData, ok bool) {
+	var v interface{}
+	if v, ok = e.Get(path); ok {
+		var m map[string]interface{}
+		m, ok = toMap(v)
+		d = EventData(m)
+	}
+	return
+}
+
 type Event struct {
 	Level   Level     `json:"level"`
 	Time    time.Time `json:"time"`
@@ -116,6 +277,102 @@ func copyEventData(data ...EventData) EventData {
 	return copy
 }
 
+// lookup resolves dot-separated paths, keys that contain dots themselves are
+// matched first so flattened and nested data are both supported.
+func lookup(v interface{}, path string) (interface{}, bool) {
+	switch x := v.(type) {
+	case EventData:
+		return lookup(map[string]interface{}(x), path)
+
+	case map[string]interface{}:
+		if r, ok := x[path]; ok {
+			return r, true
+		}
+		for i := 0; i < len(path); i++ {
+			if path[i] == '.' {
+				if r, ok := x[path[:i]]; ok {
+					if r, ok = lookup(r, path[i+1:]); ok {
+						return r, true
+					}
+				}
+			}
+		}
+
+	case []interface{}:
+		head, tail := path, ""
+		if i := strings.IndexByte(path, '.'); i >= 0 {
+			head, tail = path[:i], path[i+1:]
+		}
+		if i, err := strconv.Atoi(head); err == nil && i >= 0 && i < len(x) {
+			if len(tail) == 0 {
+				return x[i], true
+			}
+			return lookup(x[i], tail)
+		}
+	}
+
+	return nil, false
+}
+
+func toMap(v interface{}) (map[string]interface{}, bool) {
+	switch x := v.(type) {
+	case EventData:
+		return x, true
+	case map[string]interface{}:
+		return x, true
+	}
+	return nil, false
+}
+
+func toInt(v interface{}) (int64, bool) {
+	switch x := v.(type) {
+	case int:
+		return int64(x), true
+	case int8:
+		return int64(x), true
+	case int16:
+		return int64(x), true
+	case int32:
+		return int64(x), true
+	case int64:
+		return x, true
+	case uint:
+		return int64(x), uint64(x) <= math.MaxInt64
+	case uint8:
+		return int64(x), true
+	case uint16:
+		return int64(x), true
+	case uint32:
+		return int64(x), true
+	case uint64:
+		return int64(x), x <= math.MaxInt64
+	case float32:
+		return int64(x), float32(int64(x)) == x
+	case float64:
+		return int64(x), float64(int64(x)) == x
+	case json.Number:
+		i, err := x.Int64()
+		return i, err == nil
+	}
+	return 0, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float32:
+		return float64(x), true
+	case float64:
+		return x, true
+	case json.Number:
+		f, err := x.Float64()
+		return f, err == nil
+	}
+	if i, ok := toInt(v); ok {
+		return float64(i), true
+	}
+	return 0, false
+}
+
 func sprintf(format string, args ...interface{}) string {
 	return fmt.Sprintf(format, args...)
 }
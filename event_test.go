@@ -1,10 +1,17 @@
 package ecslogs
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"os"
+	"strings"
 	"syscall"
 	"testing"
+
+	"github.com/segmentio/encoding/json"
 )
 
 func TestEvent(t *testing.T) {
@@ -47,3 +54,127 @@ type fakeError struct {
 func (e *fakeError) Error() string {
 	return e.msg
 }
+
+func TestEventDataGet(t *testing.T) {
+	var data EventData
+
+	if err := json.Unmarshal([]byte(`{
+		"http.method": "GET",
+		"http": {"status": 200, "latency": 0.5, "ok": true},
+		"items": [{"id": 1}, {"id": 2}],
+		"user": {"name": "Luke"}
+	}`), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	data["count"] = 42
+
+	if s, ok := data.GetString("http.method"); !ok || s != "GET" {
+		t.Errorf("http.method: %#v", s)
+	}
+
+	if i, ok := data.GetInt("http.status"); !ok || i != 200 {
+		t.Errorf("http.status: %#v", i)
+	}
+
+	if f, ok := data.GetFloat("http.latency"); !ok || f != 0.5 {
+		t.Errorf("http.latency: %#v", f)
+	}
+
+	if b, ok := data.GetBool("http.ok"); !ok || !b {
+		t.Errorf("http.ok: %#v", b)
+	}
+
+	if i, ok := data.GetInt("items.1.id"); !ok || i != 2 {
+		t.Errorf("items.1.id: %#v", i)
+	}
+
+	if i, ok := data.GetInt("count"); !ok || i != 42 {
+		t.Errorf("count: %#v", i)
+	}
+
+	data["big"] = uint64(math.MaxUint64)
+
+	if i, ok := data.GetInt("big"); ok {
+		t.Errorf("big: %#v", i)
+	}
+
+	if d, ok := data.GetData("user"); !ok || d["name"] != "Luke" {
+		t.Errorf("user: %#v", d)
+	}
+
+	for _, path := range []string{"", "http.none", "items.2.id", "user.name.first", "http.status.code"} {
+		if v, ok := data.Get(path); ok {
+			t.Errorf("%s: unexpected value found: %#v", path, v)
+		}
+	}
+
+	if _, ok := data.GetString("http.status"); ok {
+		t.Error("http.status: unexpected string value")
+	}
+}
+
+type causeError struct {
+	msg   string
+	cause error
+}
+
+func (e *causeError) Error() string { return e.msg + ": " + e.cause.Error() }
+func (e *causeError) Cause() error  { return e.cause }
+
+type multiError []error
+
+func (m multiError) Error() string   { return "multiple errors" }
+func (m multiError) Unwrap() []error { return m }
+
+func TestMakeEventErrorChain(t *testing.T) {
+	_, errOpen := os.Open("/this/file/does/not/exist")
+
+	tests := []struct {
+		err error
+		s   string
+	}{
+		{
+			err: fmt.Errorf("reading config: %w", io.EOF),
+			s:   `{"type":"*fmt.wrapError","error":"reading config: EOF","origError":{},"causes":[{"type":"*errors.errorString","error":"EOF","depth":1}]}`,
+		},
+		{
+			err: fmt.Errorf("loading: %w", errOpen),
+			s:   `{"type":"*fmt.wrapError","error":"loading: open /this/file/does/not/exist: no such file or directory","errno":2,"op":"open","path":"/this/file/does/not/exist","origError":{},"causes":[{"type":"*fs.PathError","error":"open /this/file/does/not/exist: no such file or directory","depth":1},{"type":"syscall.Errno","error":"no such file or directory","errno":2,"depth":2}]}`,
+		},
+		{
+			err: &causeError{"dial", multiError{syscall.ECONNREFUSED, io.ErrUnexpectedEOF}},
+			s:   `{"type":"*ecslogs.causeError","error":"dial: multiple errors","errno":111,"origError":{},"causes":[{"type":"ecslogs.multiError","error":"multiple errors","depth":1},{"type":"syscall.Errno","error":"connection refused","errno":111,"depth":2},{"type":"*errors.errorString","error":"unexpected EOF","depth":2}]}`,
+		},
+		{
+			err: errors.Join(errors.New("x"), fmt.Errorf("y: %w", errors.New("z"))),
+			s:   `{"type":"*errors.joinError","error":"x\ny: z","origError":{},"causes":[{"type":"*errors.errorString","error":"x","depth":1},{"type":"*fmt.wrapError","error":"y: z","depth":1},{"type":"*errors.errorString","error":"z","depth":2}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		b, err := AppendEvent(nil, Event{Info: EventInfo{Errors: []EventError{MakeEventError(test.err)}}})
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		s := string(b)
+		s = s[strings.Index(s, `"errors":[`)+10 : strings.Index(s, `]},"data"`)]
+
+		if s != test.s {
+			t.Errorf("\n- expected: %s\n- found:    %s", test.s, s)
+		}
+	}
+}
+
+func TestMakeEventErrorContext(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(io.ErrClosedPipe)
+
+	e := MakeEventErrorContext(ctx, ctx.Err())
+
+	if len(e.Causes) != 1 || e.Causes[0].Error != io.ErrClosedPipe.Error() {
+		t.Errorf("invalid causes: %#v", e.Causes)
+	}
+}
@@ -0,0 +1,41 @@
+package ecslogs
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// exitFlushTimeout bounds the time spent flushing outputs before exiting.
+const exitFlushTimeout = 5 * time.Second
+
+// FlushWriter flushes the data buffered by w, if any. Writers implementing
+// Flusher, or a Flush or Sync method like bufio.Writer and os.File do, are
+// supported.
+func FlushWriter(ctx context.Context, w io.Writer) error {
+	switch f := w.(type) {
+	case Flusher:
+		return f.Flush(ctx)
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Sync() error }:
+		return f.Sync()
+	default:
+		return nil
+	}
+}
+
+// ShouldExit returns true if logging an event at level must terminate the
+// program when the exit policy is set to exitLevel, NONE disabling it.
+func ShouldExit(level Level, exitLevel Level) bool {
+	return level != NONE && exitLevel != NONE && level <= exitLevel
+}
+
+// ExitAfterFlush flushes w, waiting at most 5 seconds, and terminates the
+// program by calling exit with code.
+func ExitAfterFlush(w io.Writer, exit func(int), code int) {
+	ctx, cancel := context.WithTimeout(context.Background(), exitFlushTimeout)
+	FlushWriter(ctx, w)
+	cancel()
+	exit(code)
+}
@@ -0,0 +1,91 @@
+package ecslogs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestShouldExit(t *testing.T) {
+	tests := []struct {
+		level     Level
+		exitLevel Level
+		exit      bool
+	}{
+		{EMERG, CRIT, true},
+		{CRIT, CRIT, true},
+		{ERROR, CRIT, false},
+		{NONE, CRIT, false},
+		{EMERG, NONE, false},
+	}
+
+	for _, test := range tests {
+		if exit := ShouldExit(test.level, test.exitLevel); exit != test.exit {
+			t.Errorf("%s/%s: expected %t but found %t", test.level, test.exitLevel, test.exit, exit)
+		}
+	}
+}
+
+func TestFlushWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := bufio.NewWriter(buf)
+	w.WriteString("hello")
+
+	if err := FlushWriter(context.Background(), w); err != nil {
+		t.Error(err)
+	}
+
+	if s := buf.String(); s != "hello" {
+		t.Error("buffered data was not flushed:", s)
+	}
+
+	if err := FlushWriter(context.Background(), buf); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLoggerExit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := bufio.NewWriter(buf)
+	codes := []int{}
+
+	log := NewLoggerWith(Config{
+		Output:      w,
+		ExitOnLevel: CRIT,
+		ExitCode:    3,
+		Exit:        func(code int) { codes = append(codes, code) },
+		Hooks: []Hook{
+			func(e *Event) bool { return e.Level != EMERG },
+		},
+	})
+
+	log.Log(Eprint(ERROR, "A"))
+
+	if len(codes) != 0 || buf.Len() != 0 {
+		t.Fatal("the program exited on an ERROR event")
+	}
+
+	log.Log(Eprint(CRIT, "B"))
+
+	if len(codes) != 1 || codes[0] != 3 {
+		t.Error("invalid exit codes:", codes)
+	}
+
+	r := NewReader(buf)
+
+	for _, msg := range []string{"A", "B"} {
+		if e, err := r.ReadEvent(); err != nil {
+			t.Error(err)
+		} else if e.Message != msg {
+			t.Error("invalid event flushed before exiting:", e.Message)
+		}
+	}
+
+	// Events dropped by hooks still terminate the program.
+	log.Log(Eprint(EMERG, "C"))
+
+	if len(codes) != 2 {
+		t.Error("invalid exit codes:", codes)
+	}
+}
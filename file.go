@@ -0,0 +1,420 @@
+package ecslogs
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// backupTimeFormat is the format of the time added to the names of rotated
+// files, "app.log" is renamed to "app-2006-01-02T15-04-05.000.log", or to
+// "app-2006-01-02T15-04-05.000.1.log" and so on when the file already exists.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+type FileConfig struct {
+	Path string
+	Perm os.FileMode
+
+	// The file is rotated when writing would make it larger than MaxSize
+	// bytes, and when a multiple of Interval has elapsed since the zero time
+	// (so an interval of 24h rotates at midnight UTC). Zero values disable
+	// the rotation policy.
+	MaxSize  int64
+	Interval time.Duration
+
+	// Rotated files beyond the MaxBackups most recent ones, or older than
+	// MaxAge, are removed. Zero values keep all of them.
+	MaxBackups int
+	MaxAge     time.Duration
+
+	// When Compress is true rotated files are gzip-compressed in the
+	// background.
+	Compress bool
+
+	// The file is reopened when the program receives one of Signals, which
+	// is SIGHUP by default.
+	Signals []os.Signal
+
+	Now func() time.Time
+}
+
+// FileWriter is an io.Writer that writes to a file and rotates it.
+//
+// The file is opened with O_APPEND and each call to Write is done with a
+// single write, so events are never interleaved when multiple processes share
+// the file. The size of the file only accounts for the writes made by the
+// FileWriter itself, and a file that was already rotated by another process
+// is reopened instead of being rotated again.
+type FileWriter struct {
+	config FileConfig
+	mutex  sync.Mutex
+	file   *os.File
+	size   int64
+	next   time.Time
+	closed bool
+	mill   chan struct{}
+	sig    chan os.Signal
+	done   chan struct{}
+	join   sync.WaitGroup
+}
+
+func NewFileWriter(c FileConfig) (*FileWriter, error) {
+	if c.Perm == 0 {
+		c.Perm = 0644
+	}
+
+	if len(c.Signals) == 0 {
+		c.Signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+
+	w := &FileWriter{
+		config: c,
+		mill:   make(chan struct{}, 1),
+		sig:    make(chan os.Signal, 1),
+		done:   make(chan struct{}),
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	signal.Notify(w.sig, c.Signals...)
+	w.join.Add(1)
+	go w.run()
+
+	// Backups left over by a previous run may need to be cleaned up.
+	w.schedule()
+	return w, nil
+}
+
+func (w *FileWriter) Write(b []byte) (n int, err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	if w.shouldRotate(len(b)) {
+		if err = w.rotate(); err != nil {
+			return
+		}
+	}
+
+	n, err = w.file.Write(b)
+	w.size += int64(n)
+	return
+}
+
+// Rotate renames the current file to a backup and opens a new one.
+func (w *FileWriter) Rotate() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.closed {
+		return ErrClosed
+	}
+
+	return w.rotate()
+}
+
+// Reopen closes the file and opens it again, it is useful when the file was
+// moved by another program like logrotate.
+func (w *FileWriter) Reopen() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.closed {
+		return ErrClosed
+	}
+
+	return w.reopen()
+}
+
+func (w *FileWriter) Sync() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.closed {
+		return ErrClosed
+	}
+
+	return w.file.Sync()
+}
+
+// Close closes the file and waits for the background compression and cleanup
+// of rotated files to complete.
+func (w *FileWriter) Close() error {
+	w.mutex.Lock()
+
+	if w.closed {
+		w.mutex.Unlock()
+		return nil
+	}
+
+	w.closed = true
+	err := w.file.Close()
+	w.mutex.Unlock()
+
+	signal.Stop(w.sig)
+	close(w.done)
+	w.join.Wait()
+	return err
+}
+
+func (w *FileWriter) shouldRotate(n int) bool {
+	if w.config.MaxSize > 0 && w.size > 0 && w.size+int64(n) > w.config.MaxSize {
+		return true
+	}
+	return !w.next.IsZero() && !w.config.Now().Before(w.next)
+}
+
+func (w *FileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.config.Path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(w.config.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, w.config.Perm)
+	if err != nil {
+		return err
+	}
+
+	s, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	w.file, w.size = f, s.Size()
+
+	if w.config.Interval > 0 {
+		w.next = w.config.Now().Truncate(w.config.Interval).Add(w.config.Interval)
+	}
+
+	return nil
+}
+
+func (w *FileWriter) reopen() error {
+	w.file.Close()
+	return w.open()
+}
+
+func (w *FileWriter) rotate() error {
+	// Another process sharing the file may have rotated it already, in which
+	// case the new file only has to be opened.
+	if s1, err := w.file.Stat(); err == nil {
+		if s2, err := os.Stat(w.config.Path); err == nil && os.SameFile(s1, s2) {
+			if err := os.Rename(w.config.Path, w.backupName(w.config.Now())); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := w.reopen(); err != nil {
+		return err
+	}
+
+	w.schedule()
+	return nil
+}
+
+func (w *FileWriter) schedule() {
+	select {
+	case w.mill <- struct{}{}:
+	default:
+	}
+}
+
+func (w *FileWriter) run() {
+	defer w.join.Done()
+
+	for {
+		select {
+		case <-w.mill:
+			w.millBackups()
+		case <-w.sig:
+			w.Reopen()
+		case <-w.done:
+			select {
+			case <-w.mill:
+				w.millBackups()
+			default:
+			}
+			return
+		}
+	}
+}
+
+type backupFile struct {
+	path string
+	time time.Time
+	seq  int
+}
+
+// millBackups compresses and removes rotated files according to the
+// configured retention.
+func (w *FileWriter) millBackups() {
+	var minTime time.Time
+
+	if w.config.MaxAge > 0 {
+		minTime = w.config.Now().Add(-w.config.MaxAge)
+	}
+
+	for i, b := range w.backups() {
+		expired := (w.config.MaxBackups > 0 && i >= w.config.MaxBackups) ||
+			(w.config.MaxAge > 0 && b.time.Before(minTime))
+
+		switch {
+		case expired:
+			os.Remove(b.path)
+		case w.config.Compress && !strings.HasSuffix(b.path, ".gz"):
+			compressFile(b.path)
+		}
+	}
+}
+
+// backups returns the rotated files, most recent first.
+func (w *FileWriter) backups() []backupFile {
+	dir, prefix, ext := w.splitPath()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	backups := make([]backupFile, 0, len(entries))
+
+	for _, e := range entries {
+		name := e.Name()
+
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		s := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz")
+
+		if !strings.HasSuffix(s, ext) {
+			continue
+		}
+
+		s, seq := strings.TrimSuffix(s, ext), 0
+
+		if n := len(backupTimeFormat); len(s) > n {
+			if s[n] != '.' {
+				continue
+			}
+			if seq, err = strconv.Atoi(s[n+1:]); err != nil || seq <= 0 {
+				continue
+			}
+			s = s[:n]
+		}
+
+		t, err := time.Parse(backupTimeFormat, s)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), time: t, seq: seq})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].time.Equal(backups[j].time) {
+			return backups[i].time.After(backups[j].time)
+		}
+		return backups[i].seq > backups[j].seq
+	})
+
+	return backups
+}
+
+// backupName returns the name of a file that the current one can be renamed
+// to without overwriting a previous backup, compressed or not.
+func (w *FileWriter) backupName(t time.Time) string {
+	dir, prefix, ext := w.splitPath()
+	base := prefix + t.UTC().Format(backupTimeFormat)
+
+	for seq := 0; ; seq++ {
+		name := base
+		if seq != 0 {
+			name += "." + strconv.Itoa(seq)
+		}
+		path := filepath.Join(dir, name+ext)
+
+		if !fileExists(path) && !fileExists(path+".gz") {
+			return path
+		}
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return !os.IsNotExist(err)
+}
+
+func (w *FileWriter) splitPath() (dir string, prefix string, ext string) {
+	dir, base := filepath.Dir(w.config.Path), filepath.Base(w.config.Path)
+	ext = filepath.Ext(base)
+	prefix = strings.TrimSuffix(base, ext) + "-"
+	return
+}
+
+func compressFile(path string) (err error) {
+	r, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	s, err := r.Stat()
+	if err != nil {
+		return
+	}
+
+	tmp := path + ".gz.tmp"
+
+	// Another process sharing the file may be compressing the same backup,
+	// in which case it is left to it.
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, s.Mode())
+	if err != nil {
+		if os.IsExist(err) {
+			err = nil
+		}
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			os.Remove(tmp)
+		}
+	}()
+
+	z := gzip.NewWriter(f)
+
+	if _, err = io.Copy(z, r); err == nil {
+		err = z.Close()
+	}
+
+	if e := f.Close(); err == nil {
+		err = e
+	}
+
+	if err == nil {
+		if err = os.Rename(tmp, path+".gz"); err == nil {
+			err = os.Remove(path)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,299 @@
+package ecslogs
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func readFile(t *testing.T, path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func readGzipFile(t *testing.T, path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	z, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := io.ReadAll(z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func globFiles(t *testing.T, pattern string) []string {
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestFileWriterMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	w, err := NewFileWriter(FileConfig{
+		Path:       filepath.Join(dir, "app.log"),
+		MaxSize:    4,
+		MaxBackups: 2,
+		Now: func() time.Time {
+			now = now.Add(time.Second)
+			return now
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"A\n", "BBBBBBB\n", "C\n", "D\n", "E\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := readFile(t, filepath.Join(dir, "app.log")); s != "E\n" {
+		t.Errorf("invalid file content: %q", s)
+	}
+
+	backups := globFiles(t, filepath.Join(dir, "app-*.log"))
+
+	if len(backups) != 2 {
+		t.Fatal("invalid backups:", backups)
+	}
+
+	if s := readFile(t, backups[0]) + readFile(t, backups[1]); s != "BBBBBBB\nC\nD\n" {
+		t.Errorf("invalid backups content: %q", s)
+	}
+}
+
+func TestFileWriterSameTime(t *testing.T) {
+	dir := t.TempDir()
+
+	w, err := NewFileWriter(FileConfig{
+		Path:       filepath.Join(dir, "app.log"),
+		MaxSize:    2,
+		MaxBackups: 2,
+		Now:        func() time.Time { return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"A\n", "B\n", "C\n", "D\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups := globFiles(t, filepath.Join(dir, "app-*.log"))
+
+	if len(backups) != 2 {
+		t.Fatal("invalid backups:", backups)
+	}
+
+	// The most recent backups are kept even though they have the same time.
+	if s := readFile(t, backups[0]) + readFile(t, backups[1]); s != "B\nC\n" {
+		t.Errorf("invalid backups content: %q", s)
+	}
+}
+
+func TestFileWriterInterval(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2020, 1, 1, 23, 0, 0, 0, time.UTC)
+
+	w, err := NewFileWriter(FileConfig{
+		Path:     filepath.Join(dir, "app.log"),
+		Interval: 24 * time.Hour,
+		Compress: true,
+		Now:      func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.Write([]byte("A\n"))
+	now = now.Add(30 * time.Minute)
+	w.Write([]byte("B\n"))
+	now = now.Add(time.Hour)
+	w.Write([]byte("C\n"))
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s := readFile(t, filepath.Join(dir, "app.log")); s != "C\n" {
+		t.Errorf("invalid file content: %q", s)
+	}
+
+	backups := globFiles(t, filepath.Join(dir, "app-*"))
+
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], "app-2020-01-02T00-30-00.000.log.gz") {
+		t.Fatal("invalid backups:", backups)
+	}
+
+	if s := readGzipFile(t, backups[0]); s != "A\nB\n" {
+		t.Errorf("invalid backup content: %q", s)
+	}
+}
+
+func TestCompressFileConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app-2020-01-01T00-00-00.000.log")
+
+	if err := os.WriteFile(path, []byte("A\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path+".gz.tmp", []byte("partial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := compressFile(path); err != nil {
+		t.Error(err)
+	}
+
+	if s := readFile(t, path); s != "A\n" {
+		t.Errorf("invalid file content: %q", s)
+	}
+
+	if s := readFile(t, path+".gz.tmp"); s != "partial" {
+		t.Errorf("the file being compressed by another process was modified: %q", s)
+	}
+}
+
+func TestFileWriterMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2020, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	for _, name := range []string{
+		"app-2020-01-01T00-00-00.000.log.gz",
+		"app-2020-01-09T00-00-00.000.log",
+		"app.txt",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := NewFileWriter(FileConfig{
+		Path:   filepath.Join(dir, "app.log"),
+		MaxAge: 7 * 24 * time.Hour,
+		Now:    func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	files := globFiles(t, filepath.Join(dir, "*"))
+
+	if len(files) != 3 || filepath.Base(files[0]) != "app-2020-01-09T00-00-00.000.log" {
+		t.Error("invalid files after cleanup:", files)
+	}
+}
+
+func TestFileWriterReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	w, err := NewFileWriter(FileConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	w.Write([]byte("A\n"))
+
+	// Simulates logrotate moving the file and sending SIGHUP.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	w.sig <- syscall.SIGHUP
+
+	for i := 0; i != 100; i++ {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	w.Write([]byte("B\n"))
+
+	if s := readFile(t, path+".1"); s != "A\n" {
+		t.Errorf("invalid moved file content: %q", s)
+	}
+
+	if s := readFile(t, path); s != "B\n" {
+		t.Errorf("invalid reopened file content: %q", s)
+	}
+}
+
+func TestFileWriterShared(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	w1, _ := NewFileWriter(FileConfig{Path: path, MaxSize: 3})
+	w2, _ := NewFileWriter(FileConfig{Path: path, MaxSize: 3})
+
+	w1.Write([]byte("A\n"))
+	w2.Write([]byte("B\n"))
+	w1.Write([]byte("C\n")) // rotates
+	w2.Write([]byte("D\n")) // rotates, the file was already moved by w1
+	w1.Close()
+	w2.Close()
+
+	if s := readFile(t, path); s != "C\nD\n" {
+		t.Errorf("invalid file content: %q", s)
+	}
+
+	if backups := globFiles(t, filepath.Join(dir, "app-*.log")); len(backups) != 1 {
+		t.Error("invalid backups:", backups)
+	} else if s := readFile(t, backups[0]); s != "A\nB\n" {
+		t.Errorf("invalid backup content: %q", s)
+	}
+}
+
+func TestFileWriterLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	w, err := NewFileWriter(FileConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	NewLogger(w).Log(Eprint(INFO, "hello"))
+	w.Close()
+
+	if _, err := w.Write(nil); err != ErrClosed {
+		t.Error("invalid error writing to a closed file:", err)
+	}
+
+	if s := readFile(t, path); !strings.Contains(s, `"message":"hello"`) {
+		t.Error("invalid file content:", s)
+	}
+}
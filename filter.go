@@ -0,0 +1,77 @@
+package ecslogs
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// AtomicLevel is a level that can be safely read and changed while loggers
+// are using it, it implements flag.Value so it can be set from the command
+// line.
+type AtomicLevel struct {
+	lvl int32
+}
+
+func NewAtomicLevel(lvl Level) *AtomicLevel {
+	return &AtomicLevel{lvl: int32(lvl)}
+}
+
+func (a *AtomicLevel) Level() Level {
+	return Level(atomic.LoadInt32(&a.lvl))
+}
+
+func (a *AtomicLevel) SetLevel(lvl Level) {
+	atomic.StoreInt32(&a.lvl, int32(lvl))
+}
+
+func (a *AtomicLevel) Enabled(lvl Level) bool {
+	return lvl <= a.Level()
+}
+
+func (a *AtomicLevel) String() string {
+	return a.Level().String()
+}
+
+func (a *AtomicLevel) Get() interface{} {
+	return a.Level()
+}
+
+func (a *AtomicLevel) Set(s string) (err error) {
+	var lvl Level
+
+	if err = lvl.Set(s); err == nil {
+		a.SetLevel(lvl)
+	}
+
+	return
+}
+
+func (a *AtomicLevel) MarshalText() ([]byte, error) {
+	return a.Level().MarshalText()
+}
+
+func (a *AtomicLevel) UnmarshalText(b []byte) error {
+	return a.Set(string(b))
+}
+
+// LevelFilter is a logger dropping events less severe than a level that can
+// be changed while the program runs.
+type LevelFilter struct {
+	logger Logger
+	level  *AtomicLevel
+}
+
+func NewLevelFilter(logger Logger, lvl *AtomicLevel) *LevelFilter {
+	return &LevelFilter{logger: logger, level: lvl}
+}
+
+func (f *LevelFilter) Log(event Event) error {
+	if !f.level.Enabled(event.Level) {
+		return nil
+	}
+	return f.logger.Log(event)
+}
+
+func (f *LevelFilter) Flush(ctx context.Context) error {
+	return flushLogger(ctx, f.logger)
+}
@@ -0,0 +1,52 @@
+package ecslogs
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestAtomicLevelFlag(t *testing.T) {
+	lvl := NewAtomicLevel(INFO)
+	set := flag.NewFlagSet("ecslogs", flag.ContinueOnError)
+	set.SetOutput(io.Discard)
+	set.Var(lvl, "log-level", "")
+
+	if err := set.Parse([]string{"-log-level", "debug"}); err != nil {
+		t.Error(err)
+	} else if lvl.Level() != DEBUG {
+		t.Error("invalid log level parsed from command line arguments:", lvl)
+	}
+
+	if err := set.Parse([]string{"-log-level", "loud"}); err == nil {
+		t.Error("no error returned when parsing an invalid log level")
+	} else if lvl.Level() != DEBUG {
+		t.Error("log level changed after parsing an invalid value:", lvl)
+	}
+}
+
+func TestLevelFilter(t *testing.T) {
+	var msgs []string
+
+	lvl := NewAtomicLevel(WARN)
+	log := NewLevelFilter(LoggerFunc(func(e Event) error {
+		msgs = append(msgs, e.Message)
+		return nil
+	}), lvl)
+
+	log.Log(Eprint(ERROR, "A"))
+	log.Log(Eprint(WARN, "B"))
+	log.Log(Eprint(INFO, "C"))
+
+	lvl.SetLevel(TRACE)
+	log.Log(Eprint(TRACE, "D"))
+
+	lvl.SetLevel(EMERG)
+	log.Log(Eprint(ALERT, "E"))
+	log.Log(Eprint(EMERG, "F"))
+
+	if s := fmt.Sprint(msgs); s != "[A B D F]" {
+		t.Error("invalid messages logged:", s)
+	}
+}
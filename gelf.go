@@ -0,0 +1,377 @@
+package ecslogs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+)
+
+type GELFCompression int
+
+const (
+	GELFUncompressed GELFCompression = iota
+	GELFGzip
+	GELFZlib
+)
+
+const (
+	// DefaultGELFChunkSize is the maximum size of the UDP datagrams sent by
+	// GELF loggers, it fits in the MTU of most networks.
+	DefaultGELFChunkSize = 1420
+
+	gelfChunkHeaderSize = 12
+	gelfMaxChunks       = 128
+)
+
+var ErrGELFTooLarge = errors.New("ecslogs: GELF message exceeds 128 chunks")
+
+type GELFConfig struct {
+	// Network is "udp" or "tcp" (or their variants like "udp4"), messages
+	// sent over TCP are delimited by null bytes and can't be compressed.
+	Network string
+	Address string
+
+	Compression GELFCompression
+	ChunkSize   int
+
+	// Host is set on messages of events that don't have one in their info,
+	// it defaults to the host name.
+	Host string
+
+	Timeout time.Duration
+}
+
+// GELFLogger is a logger sending events to a Graylog server.
+type GELFLogger struct {
+	config GELFConfig
+	mutex  sync.Mutex
+	conn   net.Conn
+	stream bool
+	buf    bytes.Buffer
+}
+
+func NewGELFLogger(c GELFConfig) (*GELFLogger, error) {
+	if len(c.Network) == 0 {
+		c.Network = "udp"
+	}
+
+	if c.ChunkSize <= gelfChunkHeaderSize {
+		c.ChunkSize = DefaultGELFChunkSize
+	}
+
+	if len(c.Host) == 0 {
+		c.Host, _ = os.Hostname()
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = 5 * time.Second
+	}
+
+	g := &GELFLogger{config: c}
+
+	switch c.Network {
+	case "udp", "udp4", "udp6":
+	case "tcp", "tcp4", "tcp6":
+		if c.Compression != GELFUncompressed {
+			return nil, errors.New("ecslogs: GELF messages sent over TCP can't be compressed")
+		}
+		g.stream = true
+	default:
+		return nil, fmt.Errorf("ecslogs: unsupported GELF network: %q", c.Network)
+	}
+
+	if err := g.connect(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func (g *GELFLogger) Log(event Event) (err error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.conn == nil {
+		if err = g.connect(); err != nil {
+			return
+		}
+	}
+
+	b, err := AppendGELF(nil, event, g.config.Host)
+	if err != nil {
+		return
+	}
+
+	g.conn.SetWriteDeadline(time.Now().Add(g.config.Timeout))
+
+	if g.stream {
+		_, err = g.conn.Write(append(b, 0))
+	} else {
+		err = g.writeChunks(b)
+	}
+
+	if err != nil && err != ErrGELFTooLarge && g.stream {
+		g.conn.Close()
+		g.conn = nil
+	}
+
+	return
+}
+
+func (g *GELFLogger) Close() (err error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if g.conn != nil {
+		err = g.conn.Close()
+		g.conn = nil
+	}
+
+	return
+}
+
+func (g *GELFLogger) connect() (err error) {
+	g.conn, err = net.DialTimeout(g.config.Network, g.config.Address, g.config.Timeout)
+	return
+}
+
+func (g *GELFLogger) writeChunks(b []byte) (err error) {
+	if b, err = g.compress(b); err != nil {
+		return
+	}
+
+	if len(b) <= g.config.ChunkSize {
+		_, err = g.conn.Write(b)
+		return
+	}
+
+	size := g.config.ChunkSize - gelfChunkHeaderSize
+	count := (len(b) + size - 1) / size
+
+	if count > gelfMaxChunks {
+		return ErrGELFTooLarge
+	}
+
+	chunk := make([]byte, gelfChunkHeaderSize, g.config.ChunkSize)
+	chunk[0], chunk[1] = 0x1e, 0x0f
+
+	if _, err = rand.Read(chunk[2:10]); err != nil {
+		return
+	}
+
+	for i := 0; i != count; i++ {
+		n := size
+		if n > len(b) {
+			n = len(b)
+		}
+
+		chunk[10], chunk[11] = byte(i), byte(count)
+
+		if _, err = g.conn.Write(append(chunk[:gelfChunkHeaderSize], b[:n]...)); err != nil {
+			return
+		}
+
+		b = b[n:]
+	}
+
+	return
+}
+
+func (g *GELFLogger) compress(b []byte) ([]byte, error) {
+	var w interface {
+		Write([]byte) (int, error)
+		Close() error
+	}
+
+	g.buf.Reset()
+
+	switch g.config.Compression {
+	case GELFGzip:
+		w = gzip.NewWriter(&g.buf)
+	case GELFZlib:
+		w = zlib.NewWriter(&g.buf)
+	default:
+		return b, nil
+	}
+
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return g.buf.Bytes(), nil
+}
+
+// AppendGELF appends the GELF 1.1 encoding of event to b. The first line of
+// the message is used as short message, and the full message is only set
+// when there are more. Event data are flattened into additional fields with
+// underscore-separated names, values that aren't strings or numbers are
+// encoded to JSON strings.
+func AppendGELF(b []byte, event Event, host string) ([]byte, error) {
+	if len(event.Info.Host) != 0 {
+		host = event.Info.Host
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	short := strings.TrimSpace(event.Message)
+
+	if i := strings.IndexByte(short, '\n'); i >= 0 {
+		short = strings.TrimSpace(short[:i])
+	}
+
+	if len(short) == 0 && len(event.Info.Errors) != 0 {
+		short = event.Info.Errors[0].Error
+	}
+
+	if len(short) == 0 {
+		short = "-"
+	}
+
+	b = append(b, `{"version":"1.1","host":`...)
+	b = appendString(b, host, 0)
+	b = append(b, `,"short_message":`...)
+	b = appendString(b, short, 0)
+
+	if short != event.Message && len(event.Message) != 0 {
+		b = append(b, `,"full_message":`...)
+		b = appendString(b, event.Message, 0)
+	}
+
+	b = append(b, `,"timestamp":`...)
+	b = strconv.AppendFloat(b, float64(event.Time.UnixNano()/1e6)/1e3, 'f', 3, 64)
+	b = append(b, `,"level":`...)
+	b = strconv.AppendInt(b, int64(event.Level.Severity()), 10)
+
+	fields := make(map[string]interface{}, len(event.Data)+8)
+	flattenGELFData(fields, "_", event.Data)
+	addGELFInfo(fields, event.Info)
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var err error
+		b = append(b, ',')
+		b = appendString(b, name, 0)
+		b = append(b, ':')
+
+		if b, err = appendGELFValue(b, fields[name]); err != nil {
+			return b, err
+		}
+	}
+
+	return append(b, '}'), nil
+}
+
+func addGELFInfo(fields map[string]interface{}, info EventInfo) {
+	if len(info.Source) != 0 {
+		fields["_source"] = info.Source
+	}
+
+	if len(info.ID) != 0 {
+		fields["_container_id"] = info.ID
+	}
+
+	if info.PID != 0 {
+		fields["_pid"] = info.PID
+	}
+
+	if info.UID != 0 {
+		fields["_uid"] = info.UID
+	}
+
+	if info.GID != 0 {
+		fields["_gid"] = info.GID
+	}
+
+	if len(info.Errors) != 0 && len(info.Errors[0].Error) != 0 {
+		e := info.Errors[0]
+		fields["_error"] = e.Error
+		fields["_error_type"] = e.Type
+
+		if e.Errno != 0 {
+			fields["_errno"] = e.Errno
+		}
+	}
+}
+
+// gelfInfoFields are the additional fields set by addGELFInfo.
+var gelfInfoFields = map[string]bool{
+	"_source":       true,
+	"_container_id": true,
+	"_pid":          true,
+	"_uid":          true,
+	"_gid":          true,
+	"_error":        true,
+	"_error_type":   true,
+	"_errno":        true,
+}
+
+func flattenGELFData(fields map[string]interface{}, prefix string, data EventData) {
+	for k, v := range data {
+		switch x := v.(type) {
+		case EventData:
+			flattenGELFData(fields, prefix+k+"_", x)
+		case map[string]interface{}:
+			flattenGELFData(fields, prefix+k+"_", EventData(x))
+		default:
+			// GELF reserves the _id field, data named like the fields set
+			// from the event info are prefixed so they aren't replaced.
+			if name := gelfFieldName(prefix + k); name != "_id" {
+				if gelfInfoFields[name] {
+					name = "_data" + name
+				}
+				fields[name] = v
+			}
+		}
+	}
+}
+
+// gelfFieldName replaces the characters not allowed in the names of GELF
+// additional fields with underscores.
+func gelfFieldName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+func appendGELFValue(b []byte, v interface{}) ([]byte, error) {
+	switch x := v.(type) {
+	case string:
+		return appendString(b, x, 0), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+		return appendValue(b, x, 0)
+	default:
+		s, err := appendValue(nil, v, 0)
+		if err != nil {
+			return b, err
+		}
+		return appendString(b, string(s), 0), nil
+	}
+}
@@ -0,0 +1,236 @@
+package ecslogs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendGELF(t *testing.T) {
+	tests := []struct {
+		event Event
+		gelf  string
+	}{
+		{
+			event: Event{
+				Level:   ERROR,
+				Time:    time.Date(2020, 1, 2, 3, 4, 5, 6e6, time.UTC),
+				Message: "hello",
+			},
+			gelf: `{"version":"1.1","host":"host","short_message":"hello","timestamp":1577934245.006,"level":3}`,
+		},
+		{
+			event: Event{
+				Level:   TRACE,
+				Time:    time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+				Info:    EventInfo{Host: "other", Source: "main.go:1:main", PID: 42, Errors: []EventError{{Type: "*errors.errorString", Error: "EOF"}}},
+				Message: "hello\nworld",
+				Data: EventData{
+					"id":    1,
+					"user":  EventData{"name": "me", "admin": true},
+					"tags":  []interface{}{"a", "b"},
+					"a b":   1.5,
+					"count": 3,
+					"pid":   7,
+					"error": "data",
+				},
+			},
+			gelf: `{"version":"1.1","host":"other","short_message":"hello","full_message":"hello\nworld","timestamp":1577934245.000,"level":7,` +
+				`"_a_b":1.5,"_count":3,"_data_error":"data","_data_pid":7,"_error":"EOF","_error_type":"*errors.errorString","_pid":42,"_source":"main.go:1:main",` +
+				`"_tags":"[\"a\",\"b\"]","_user_admin":"true","_user_name":"me"}`,
+		},
+		{
+			event: Event{Level: NONE, Time: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+			gelf:  `{"version":"1.1","host":"host","short_message":"-","timestamp":1577934245.000,"level":6}`,
+		},
+	}
+
+	for _, test := range tests {
+		b, err := AppendGELF(nil, test.event, "host")
+
+		if err != nil {
+			t.Error(err)
+		} else if s := string(b); s != test.gelf {
+			t.Errorf("\n- expected: %s\n- found:    %s", test.gelf, s)
+		}
+	}
+}
+
+func readGELFPacket(t *testing.T, conn net.PacketConn) []byte {
+	buf := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return append([]byte{}, buf[:n]...)
+}
+
+func TestGELFLoggerUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	log, err := NewGELFLogger(GELFConfig{
+		Address:     conn.LocalAddr().String(),
+		Compression: GELFZlib,
+		Host:        "host",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	if err := log.Log(Eprint(INFO, "hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	z, err := zlib.NewReader(bytes.NewReader(readGELFPacket(t, conn)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := io.ReadAll(z)
+
+	if s := string(b); !strings.Contains(s, `"short_message":"hello"`) {
+		t.Error("invalid GELF message:", s)
+	}
+}
+
+func TestGELFLoggerChunks(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	log, err := NewGELFLogger(GELFConfig{
+		Address:     conn.LocalAddr().String(),
+		Compression: GELFGzip,
+		ChunkSize:   100,
+		Host:        "host",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	// Random-looking data that gzip can't compress much.
+	msg := make([]byte, 2000)
+	for i, x := 0, uint32(1); i != len(msg); i++ {
+		x = x*1664525 + 1013904223
+		msg[i] = 'a' + byte(x>>24)%26
+	}
+
+	if err := log.Log(Eprint(INFO, string(msg))); err != nil {
+		t.Fatal(err)
+	}
+
+	var id []byte
+	var count int
+	var payload []byte
+
+	for i := 0; i == 0 || i < count; i++ {
+		chunk := readGELFPacket(t, conn)
+
+		if len(chunk) > 100 || chunk[0] != 0x1e || chunk[1] != 0x0f {
+			t.Fatalf("invalid chunk header: % x", chunk[:12])
+		}
+
+		if i == 0 {
+			id, count = chunk[2:10], int(chunk[11])
+		} else if !bytes.Equal(id, chunk[2:10]) || int(chunk[11]) != count {
+			t.Fatalf("invalid chunk header: % x", chunk[:12])
+		}
+
+		if int(chunk[10]) != i {
+			t.Fatal("invalid chunk sequence number:", chunk[10])
+		}
+
+		payload = append(payload, chunk[12:]...)
+	}
+
+	if count < 2 {
+		t.Fatal("the message was not chunked")
+	}
+
+	z, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := io.ReadAll(z)
+
+	if s := string(b); !strings.Contains(s, `"short_message":"`+string(msg)+`"`) {
+		t.Error("invalid GELF message:", s)
+	}
+
+	log.config.ChunkSize = 13
+
+	if err := log.Log(Eprint(INFO, string(msg))); err != ErrGELFTooLarge {
+		t.Error("invalid error for a message exceeding the maximum number of chunks:", err)
+	}
+}
+
+func TestGELFLoggerTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	msgs := make(chan string, 2)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		for {
+			s, err := r.ReadString(0)
+			if err != nil {
+				return
+			}
+			msgs <- s
+		}
+	}()
+
+	if _, err := NewGELFLogger(GELFConfig{Network: "tcp", Address: l.Addr().String(), Compression: GELFGzip}); err == nil {
+		t.Error("no error returned for compressed messages over TCP")
+	}
+
+	log, err := NewGELFLogger(GELFConfig{Network: "tcp", Address: l.Addr().String(), Host: "host"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	log.Log(Event{Level: INFO, Message: "A", Time: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)})
+	log.Log(Event{Level: INFO, Message: "B", Time: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)})
+
+	for _, msg := range []string{"A", "B"} {
+		expected := `{"version":"1.1","host":"host","short_message":"` + msg + `","timestamp":1577934245.000,"level":6}` + "\x00"
+
+		select {
+		case s := <-msgs:
+			if s != expected {
+				t.Errorf("\n- expected: %q\n- found:    %q", expected, s)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for GELF message")
+		}
+	}
+}
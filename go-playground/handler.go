@@ -1,6 +1,7 @@
 package play_ecslogs
 
 import (
+	"context"
 	"io"
 
 	"github.com/go-playground/log"
@@ -11,6 +12,14 @@ type Config struct {
 	Output   io.Writer
 	Depth    int
 	FuncInfo func(uintptr) (ecslogs.FuncInfo, bool)
+	Limits   ecslogs.Limits
+	Hooks    []ecslogs.Hook
+
+	// ExitOnLevel, ExitCode and Exit configure the exit policy of the
+	// underlying ecslogs logger.
+	ExitOnLevel ecslogs.Level
+	ExitCode    int
+	Exit        func(int)
 }
 
 func NewHandler(w io.Writer) log.Handler {
@@ -18,7 +27,15 @@ func NewHandler(w io.Writer) log.Handler {
 }
 
 func NewHandlerWith(c Config) log.Handler {
-	logger := ecslogs.NewLogger(c.Output)
+	logger := ecslogs.NewLoggerWith(ecslogs.Config{
+		Output: c.Output,
+		Limits: c.Limits,
+		Hooks:  c.Hooks,
+
+		ExitOnLevel: c.ExitOnLevel,
+		ExitCode:    c.ExitCode,
+		Exit:        c.Exit,
+	})
 
 	if c.FuncInfo == nil {
 		return &handler{fn: func(entry log.Entry) {
@@ -39,6 +56,19 @@ func NewHandlerWith(c Config) log.Handler {
 	}}
 }
 
+// WithContext returns an entry carrying the fields set on ctx with
+// ecslogs.ContextWithFields and the identifiers of its trace.
+func WithContext(ctx context.Context) log.Entry {
+	data := ecslogs.ContextData(ctx)
+	fields := make([]log.Field, 0, len(data))
+
+	for k, v := range data {
+		fields = append(fields, log.F(k, v))
+	}
+
+	return log.WithFields(fields...)
+}
+
 type handler struct {
 	fn func(entry log.Entry)
 }
@@ -2,6 +2,7 @@ package play_ecslogs
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"strings"
 	"testing"
@@ -38,3 +39,18 @@ func testFuncInfo(pc uintptr) (info ecslogs.FuncInfo, ok bool) {
 	info.Line = 42
 	return
 }
+
+func TestWithContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log.AddHandler(NewHandler(buf), log.AllLevels...)
+
+	ctx := ecslogs.ContextWithFields(context.Background(), ecslogs.EventData{"requestId": "1234"})
+	ctx = ecslogs.ContextWithTrace(ctx, ecslogs.TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736"})
+	WithContext(ctx).Info("hello")
+
+	s := strings.TrimSpace(buf.String())
+
+	if !strings.HasSuffix(s, `"data":{"requestId":"1234","traceId":"4bf92f3577b34da6a3ce929d0e0e4736"},"message":"hello"}`) {
+		t.Error("play handler failed:", s)
+	}
+}
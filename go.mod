@@ -1,11 +1,17 @@
 module github.com/segmentio/ecs-logs-go
 
-go 1.14
+go 1.20
 
 require (
 	github.com/apex/log v1.1.2
-	github.com/go-playground/errors v3.3.0+incompatible // indirect
 	github.com/go-playground/log v6.3.0+incompatible
 	github.com/segmentio/encoding v0.1.11
 	github.com/sirupsen/logrus v1.5.0
 )
+
+require (
+	github.com/go-playground/errors v3.3.0+incompatible // indirect
+	github.com/konsorten/go-windows-terminal-sequences v1.0.1 // indirect
+	github.com/pkg/errors v0.8.1 // indirect
+	golang.org/x/sys v0.0.0-20190422165155-953cdadca894 // indirect
+)
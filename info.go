@@ -0,0 +1,104 @@
+package ecslogs
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"regexp"
+	"sync"
+)
+
+var (
+	processInfoOnce sync.Once
+	processInfo     EventInfo
+)
+
+// ProcessInfo returns the host name, process, user and group IDs, and the
+// container ID of the program, it is computed once and cached.
+func ProcessInfo() EventInfo {
+	processInfoOnce.Do(func() {
+		processInfo = makeProcessInfo()
+	})
+	return processInfo
+}
+
+func makeProcessInfo() EventInfo {
+	host, _ := os.Hostname()
+	return EventInfo{
+		Host: host,
+		ID:   containerID(),
+		PID:  os.Getpid(),
+		UID:  os.Getuid(),
+		GID:  os.Getgid(),
+	}
+}
+
+// mergeEventInfo fills the fields of info that are not set with the values
+// from defaults.
+func mergeEventInfo(info EventInfo, defaults EventInfo) EventInfo {
+	if len(info.Host) == 0 {
+		info.Host = defaults.Host
+	}
+	if len(info.Source) == 0 {
+		info.Source = defaults.Source
+	}
+	if len(info.ID) == 0 {
+		info.ID = defaults.ID
+	}
+	if info.PID == 0 {
+		info.PID = defaults.PID
+	}
+	if info.UID == 0 {
+		info.UID = defaults.UID
+	}
+	if info.GID == 0 {
+		info.GID = defaults.GID
+	}
+	return info
+}
+
+func containerID() string {
+	for _, src := range []struct {
+		path  string
+		parse func(io.Reader) string
+	}{
+		{"/proc/self/cgroup", parseCgroupContainerID},
+		{"/proc/self/mountinfo", parseMountinfoContainerID},
+	} {
+		if f, err := os.Open(src.path); err == nil {
+			id := src.parse(f)
+			f.Close()
+			if len(id) != 0 {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+var (
+	// Docker and containerd use 64 hex characters IDs, Fargate uses the
+	// 32 hex characters of the task ID followed by a number.
+	cgroupContainerID    = regexp.MustCompile(`([0-9a-f]{64}|[0-9a-f]{32}-[0-9]{10})(?:\.scope)?$`)
+	mountinfoContainerID = regexp.MustCompile(`/containers/([0-9a-f]{64})/`)
+)
+
+func parseCgroupContainerID(r io.Reader) string {
+	return scanContainerID(r, cgroupContainerID)
+}
+
+func parseMountinfoContainerID(r io.Reader) string {
+	return scanContainerID(r, mountinfoContainerID)
+}
+
+func scanContainerID(r io.Reader, re *regexp.Regexp) string {
+	s := bufio.NewScanner(r)
+
+	for s.Scan() {
+		if m := re.FindStringSubmatch(s.Text()); m != nil {
+			return m[1]
+		}
+	}
+
+	return ""
+}
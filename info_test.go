@@ -0,0 +1,74 @@
+package ecslogs
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseCgroupContainerID(t *testing.T) {
+	tests := []struct {
+		cgroup string
+		id     string
+	}{
+		{
+			cgroup: "0::/\n",
+			id:     "",
+		},
+		{
+			cgroup: "12:pids:/docker/3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e\n" +
+				"11:cpu,cpuacct:/docker/3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e\n",
+			id: "3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e",
+		},
+		{
+			cgroup: "9:perf_event:/ecs/8a9b0c1d-2e3f-4a5b-6c7d-8e9f0a1b2c3d/3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e\n",
+			id:     "3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e",
+		},
+		{
+			cgroup: "1:name=systemd:/ecs/8a9b0c1d2e3f4a5b6c7d8e9f0a1b2c3d/8a9b0c1d2e3f4a5b6c7d8e9f0a1b2c3d-1234567890\n",
+			id:     "8a9b0c1d2e3f4a5b6c7d8e9f0a1b2c3d-1234567890",
+		},
+		{
+			cgroup: "0::/system.slice/docker-3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e.scope\n",
+			id:     "3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e",
+		},
+	}
+
+	for _, test := range tests {
+		if id := parseCgroupContainerID(strings.NewReader(test.cgroup)); id != test.id {
+			t.Errorf("%q: invalid container ID: %q", test.cgroup, id)
+		}
+	}
+}
+
+func TestParseMountinfoContainerID(t *testing.T) {
+	const mountinfo = `1034 1031 0:96 / /proc rw,nosuid,nodev,noexec,relatime - proc proc rw
+1057 1030 254:1 /var/lib/docker/containers/3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e/hostname /etc/hostname rw,relatime - ext4 /dev/vda1 rw
+`
+	if id := parseMountinfoContainerID(strings.NewReader(mountinfo)); id != "3f4e5d6c7b8a9f0e1d2c3b4a5f6e7d8c9b0a1f2e3d4c5b6a7f8e9d0c1b2a3f4e" {
+		t.Errorf("invalid container ID: %q", id)
+	}
+}
+
+func TestLoggerProcessInfo(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := NewLoggerWith(Config{Output: buf, ProcessInfo: true})
+
+	e := Eprint(INFO, "")
+	e.Info.Host = "localhost"
+	log.Log(e)
+
+	event, err := NewReader(buf).ReadEvent()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if event.Info.Host != "localhost" {
+		t.Error("the host set on the event was overwritten:", event.Info.Host)
+	}
+
+	if event.Info.PID != os.Getpid() {
+		t.Error("invalid pid:", event.Info.PID)
+	}
+}
@@ -0,0 +1,302 @@
+//go:build linux
+// +build linux
+
+package ecslogs
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const DefaultJournalSocket = "/run/systemd/journal/socket"
+
+type JournalConfig struct {
+	// Socket is the path to the socket of journald, it can be set to another
+	// location in tests.
+	Socket string
+
+	// Identifier is set as SYSLOG_IDENTIFIER, it defaults to the name of the
+	// program.
+	Identifier string
+}
+
+// JournalLogger is a logger writing events to journald with its native
+// protocol, event data are flattened into uppercase journal fields so
+// {"user":{"id":1}} is recorded as USER_ID=1. Data that would override the
+// fields set by the logger, like MESSAGE, are prefixed with DATA_.
+type JournalLogger struct {
+	config JournalConfig
+	mutex  sync.Mutex
+	conn   *net.UnixConn
+	addr   *net.UnixAddr
+	buf    []byte
+}
+
+func NewJournalLogger(c JournalConfig) (*JournalLogger, error) {
+	if len(c.Socket) == 0 {
+		c.Socket = DefaultJournalSocket
+	}
+
+	if len(c.Identifier) == 0 {
+		c.Identifier = filepath.Base(os.Args[0])
+	}
+
+	if _, err := os.Stat(c.Socket); err != nil {
+		return nil, err
+	}
+
+	// The socket is left unconnected so journald restarting doesn't break it.
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &JournalLogger{
+		config: c,
+		conn:   conn,
+		addr:   &net.UnixAddr{Name: c.Socket, Net: "unixgram"},
+	}, nil
+}
+
+func (j *JournalLogger) Log(event Event) (err error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.buf = appendJournalEntry(j.buf[:0], event, j.config.Identifier)
+
+	if _, _, err = j.conn.WriteMsgUnix(j.buf, nil, j.addr); err != nil {
+		if errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS) {
+			// The entry doesn't fit in a datagram, journald also accepts it
+			// written to a file passed as file descriptor.
+			err = j.sendFile(j.buf)
+		}
+	}
+
+	return
+}
+
+func (j *JournalLogger) Close() error {
+	return j.conn.Close()
+}
+
+// sendFile passes b to journald in a sealed memory file, like sd_journal_sendv
+// does, journald refuses unsealed files that aren't on a tmpfs.
+func (j *JournalLogger) sendFile(b []byte) error {
+	f, sealed, err := createJournalFile()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(b); err != nil {
+		return err
+	}
+
+	if sealed {
+		if err := sealJournalFile(f); err != nil {
+			return err
+		}
+	}
+
+	_, _, err = j.conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), j.addr)
+	return err
+}
+
+const (
+	mfdCloexec       = 0x1
+	mfdAllowSealing  = 0x2
+	fcntlAddSeals    = 1033
+	fcntlGetSeals    = 1034
+	sealJournalFlags = 0x1 | 0x2 | 0x4 | 0x8 // F_SEAL_SEAL|SHRINK|GROW|WRITE
+)
+
+// memfdCreateTrap returns the number of the memfd_create system call, which
+// the syscall package doesn't define on all architectures.
+func memfdCreateTrap() uintptr {
+	switch runtime.GOARCH {
+	case "amd64":
+		return 319
+	case "386":
+		return 356
+	case "arm":
+		return 385
+	case "arm64", "riscv64", "loong64":
+		return 279
+	case "ppc64", "ppc64le":
+		return 360
+	case "s390x":
+		return 350
+	case "mips", "mipsle":
+		return 4354
+	case "mips64", "mips64le":
+		return 5314
+	default:
+		return 0
+	}
+}
+
+// createJournalFile returns a memory file that can be sealed, or an unlinked
+// file on the tmpfs mounted at /dev/shm on kernels without memfd_create.
+func createJournalFile() (f *os.File, sealed bool, err error) {
+	if trap := memfdCreateTrap(); trap != 0 {
+		name, _ := syscall.BytePtrFromString("ecslogs-journal")
+		fd, _, errno := syscall.Syscall(trap, uintptr(unsafe.Pointer(name)), mfdCloexec|mfdAllowSealing, 0)
+		if errno == 0 {
+			return os.NewFile(fd, "ecslogs-journal"), true, nil
+		}
+	}
+
+	if f, err = os.CreateTemp("/dev/shm", "ecslogs-journal-"); err != nil {
+		return
+	}
+
+	os.Remove(f.Name())
+	return
+}
+
+func sealJournalFile(f *os.File) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), fcntlAddSeals, sealJournalFlags); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// journalReservedFields are the fields set by appendJournalEntry, event data
+// with the same names are prefixed with DATA_ so they don't override them.
+var journalReservedFields = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"SYSLOG_IDENTIFIER": true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+	"ERROR":             true,
+	"ERRNO":             true,
+}
+
+func appendJournalEntry(b []byte, event Event, identifier string) []byte {
+	b = appendJournalField(b, "MESSAGE", event.Message)
+	b = appendJournalField(b, "PRIORITY", strconv.Itoa(event.Level.Severity()))
+	b = appendJournalField(b, "SYSLOG_IDENTIFIER", identifier)
+
+	if len(event.Info.Source) != 0 {
+		// Sources are formatted as file:line:func by FuncInfo.String, some
+		// adapters only have the file and line.
+		parts := strings.SplitN(event.Info.Source, ":", 3)
+		b = appendJournalField(b, "CODE_FILE", parts[0])
+
+		if len(parts) > 1 {
+			b = appendJournalField(b, "CODE_LINE", parts[1])
+		}
+
+		if len(parts) > 2 {
+			b = appendJournalField(b, "CODE_FUNC", parts[2])
+		}
+	}
+
+	if len(event.Info.Errors) != 0 {
+		if len(event.Info.Errors[0].Error) != 0 {
+			b = appendJournalField(b, "ERROR", event.Info.Errors[0].Error)
+		}
+
+		if errno := event.Info.Errors[0].Errno; errno != 0 {
+			b = appendJournalField(b, "ERRNO", strconv.Itoa(errno))
+		}
+	}
+
+	fields := make(map[string]string, len(event.Data))
+	flattenJournalData(fields, "", event.Data)
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b = appendJournalField(b, name, fields[name])
+	}
+
+	return b
+}
+
+// appendJournalField appends a field in the format of the native journal
+// protocol, values with new lines are prefixed with their length.
+func appendJournalField(b []byte, name string, value string) []byte {
+	b = append(b, name...)
+
+	if strings.IndexByte(value, '\n') < 0 {
+		b = append(b, '=')
+		b = append(b, value...)
+	} else {
+		var size [8]byte
+		binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+		b = append(b, '\n')
+		b = append(b, size[:]...)
+		b = append(b, value...)
+	}
+
+	return append(b, '\n')
+}
+
+func flattenJournalData(fields map[string]string, prefix string, data EventData) {
+	for k, v := range data {
+		switch x := v.(type) {
+		case EventData:
+			flattenJournalData(fields, prefix+k+"_", x)
+		case map[string]interface{}:
+			flattenJournalData(fields, prefix+k+"_", EventData(x))
+		default:
+			name := journalFieldName(prefix + k)
+
+			if len(name) == 0 {
+				continue
+			}
+
+			if journalReservedFields[name] {
+				name = "DATA_" + name
+			}
+
+			if s, ok := v.(string); ok {
+				fields[name] = s
+			} else if b, err := appendValue(nil, v, 0); err == nil {
+				fields[name] = string(b)
+			}
+		}
+	}
+}
+
+// journalFieldName converts s to a valid journal field name, which only has
+// uppercase letters, digits and underscores, doesn't start with a digit or
+// an underscore (those are reserved for trusted fields), and is at most 64
+// characters long.
+func journalFieldName(s string) string {
+	b := make([]byte, 0, len(s))
+
+	for i := 0; i != len(s) && len(b) < 64; i++ {
+		switch c := s[i]; {
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			b = append(b, c)
+		case c >= 'a' && c <= 'z':
+			b = append(b, c-'a'+'A')
+		default:
+			b = append(b, '_')
+		}
+	}
+
+	for len(b) != 0 && (b[0] == '_' || (b[0] >= '0' && b[0] <= '9')) {
+		b = b[1:]
+	}
+
+	return string(b)
+}
@@ -0,0 +1,145 @@
+//go:build linux
+// +build linux
+
+package ecslogs
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestAppendJournalEntry(t *testing.T) {
+	e := Event{
+		Level:   WARN,
+		Info:    EventInfo{Source: "ecslogs/journal_test.go:42:TestAppendJournalEntry"},
+		Message: "hello\nworld",
+		Data: EventData{
+			"user":      EventData{"id": 1, "name": "me"},
+			"requestId": "1234",
+			"_secret":   true,
+			"priority":  "high",
+			"code":      EventData{"line": 1},
+		},
+	}
+
+	const expected = "MESSAGE\n\x0b\x00\x00\x00\x00\x00\x00\x00hello\nworld\n" +
+		"PRIORITY=4\n" +
+		"SYSLOG_IDENTIFIER=app\n" +
+		"CODE_FILE=ecslogs/journal_test.go\n" +
+		"CODE_LINE=42\n" +
+		"CODE_FUNC=TestAppendJournalEntry\n" +
+		"DATA_CODE_LINE=1\n" +
+		"DATA_PRIORITY=high\n" +
+		"REQUESTID=1234\n" +
+		"SECRET=true\n" +
+		"USER_ID=1\n" +
+		"USER_NAME=me\n"
+
+	if s := string(appendJournalEntry(nil, e, "app")); s != expected {
+		t.Errorf("\n- expected: %q\n- found:    %q", expected, s)
+	}
+}
+
+func TestJournalFieldName(t *testing.T) {
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{"message", "MESSAGE"},
+		{"user.id", "USER_ID"},
+		{"_trusted", "TRUSTED"},
+		{"1abc", "ABC"},
+		{"", ""},
+		{strings.Repeat("a", 100), strings.Repeat("A", 64)},
+	}
+
+	for _, test := range tests {
+		if s := journalFieldName(test.in); s != test.out {
+			t.Errorf("%q: expected %q but found %q", test.in, test.out, s)
+		}
+	}
+}
+
+func TestJournalLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "socket")
+
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+
+	log, err := NewJournalLogger(JournalConfig{Socket: path, Identifier: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	large := strings.Repeat("x", 1<<20)
+
+	if err := log.Log(Eprint(INFO, "hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := log.Log(Eprint(INFO, large)); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 4096)
+	oob := make([]byte, 64)
+
+	n, _, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := string(buf[:n]); s != "MESSAGE=hello\nPRIORITY=6\nSYSLOG_IDENTIFIER=app\n" {
+		t.Errorf("invalid journal entry: %q", s)
+	}
+
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 0 {
+		t.Fatal("large entry sent in a datagram")
+	}
+
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatal("invalid control messages:", err)
+	}
+
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatal("invalid file descriptors:", err)
+	}
+
+	f := os.NewFile(uintptr(fds[0]), "journal")
+	defer f.Close()
+
+	if seals, _, errno := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), fcntlGetSeals, 0); errno != 0 {
+		t.Log("the file isn't sealed:", errno)
+	} else if seals&sealJournalFlags != sealJournalFlags {
+		t.Errorf("invalid seals: %#x", seals)
+	}
+
+	f.Seek(0, io.SeekStart)
+
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := string(b); s != "MESSAGE="+large+"\nPRIORITY=6\nSYSLOG_IDENTIFIER=app\n" {
+		t.Errorf("invalid journal entry of %d bytes", len(s))
+	}
+}
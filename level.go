@@ -90,6 +90,19 @@ func (lvl Level) Priority() int {
 	return int(lvl - 1)
 }
 
+// Severity returns the syslog severity of lvl, NONE is mapped to INFO and
+// TRACE to DEBUG since syslog has no equivalent for them.
+func (lvl Level) Severity() int {
+	switch {
+	case lvl == NONE:
+		return INFO.Priority()
+	case lvl > DEBUG:
+		return DEBUG.Priority()
+	default:
+		return lvl.Priority()
+	}
+}
+
 func (lvl Level) GoString() string {
 	return "Level(" + strconv.Itoa(lvl.Priority()) + ")"
 }
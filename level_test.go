@@ -142,6 +142,25 @@ func TestLevelPriority(t *testing.T) {
 	}
 }
 
+func TestLevelSeverity(t *testing.T) {
+	tests := []struct {
+		lvl      Level
+		severity int
+	}{
+		{EMERG, 0},
+		{ERROR, 3},
+		{DEBUG, 7},
+		{TRACE, 7},
+		{NONE, 6},
+	}
+
+	for _, test := range tests {
+		if severity := test.lvl.Severity(); severity != test.severity {
+			t.Errorf("%s: expected severity %d but found %d", test.lvl, test.severity, severity)
+		}
+	}
+}
+
 func TestLevelFlag(t *testing.T) {
 	lvl := NONE
 	set := flag.NewFlagSet("ecslogs", flag.ContinueOnError)
@@ -0,0 +1,114 @@
+package ecslogs
+
+import (
+	"os"
+	"time"
+)
+
+// LevelLogger provides leveled logging methods on top of a Logger, events
+// are stamped with the current time and the location of the caller.
+type LevelLogger struct {
+	logger Logger
+}
+
+func NewLevelLogger(logger Logger) *LevelLogger {
+	if logger == nil {
+		logger = NewLogger(os.Stderr)
+	}
+	return &LevelLogger{logger: logger}
+}
+
+// With returns a LevelLogger which adds fields to all events it logs.
+func (l *LevelLogger) With(fields EventData) *LevelLogger {
+	return &LevelLogger{logger: With(l.logger, fields)}
+}
+
+func (l *LevelLogger) Log(event Event) error {
+	return l.logger.Log(event)
+}
+
+func (l *LevelLogger) Emerg(args ...interface{}) error {
+	return l.log(Eprint(EMERG, args...))
+}
+
+func (l *LevelLogger) Emergf(format string, args ...interface{}) error {
+	return l.log(Eprintf(EMERG, format, args...))
+}
+
+func (l *LevelLogger) Alert(args ...interface{}) error {
+	return l.log(Eprint(ALERT, args...))
+}
+
+func (l *LevelLogger) Alertf(format string, args ...interface{}) error {
+	return l.log(Eprintf(ALERT, format, args...))
+}
+
+func (l *LevelLogger) Crit(args ...interface{}) error {
+	return l.log(Eprint(CRIT, args...))
+}
+
+func (l *LevelLogger) Critf(format string, args ...interface{}) error {
+	return l.log(Eprintf(CRIT, format, args...))
+}
+
+func (l *LevelLogger) Error(args ...interface{}) error {
+	return l.log(Eprint(ERROR, args...))
+}
+
+func (l *LevelLogger) Errorf(format string, args ...interface{}) error {
+	return l.log(Eprintf(ERROR, format, args...))
+}
+
+func (l *LevelLogger) Warn(args ...interface{}) error {
+	return l.log(Eprint(WARN, args...))
+}
+
+func (l *LevelLogger) Warnf(format string, args ...interface{}) error {
+	return l.log(Eprintf(WARN, format, args...))
+}
+
+func (l *LevelLogger) Notice(args ...interface{}) error {
+	return l.log(Eprint(NOTICE, args...))
+}
+
+func (l *LevelLogger) Noticef(format string, args ...interface{}) error {
+	return l.log(Eprintf(NOTICE, format, args...))
+}
+
+func (l *LevelLogger) Info(args ...interface{}) error {
+	return l.log(Eprint(INFO, args...))
+}
+
+func (l *LevelLogger) Infof(format string, args ...interface{}) error {
+	return l.log(Eprintf(INFO, format, args...))
+}
+
+func (l *LevelLogger) Debug(args ...interface{}) error {
+	return l.log(Eprint(DEBUG, args...))
+}
+
+func (l *LevelLogger) Debugf(format string, args ...interface{}) error {
+	return l.log(Eprintf(DEBUG, format, args...))
+}
+
+func (l *LevelLogger) Trace(args ...interface{}) error {
+	return l.log(Eprint(TRACE, args...))
+}
+
+func (l *LevelLogger) Tracef(format string, args ...interface{}) error {
+	return l.log(Eprintf(TRACE, format, args...))
+}
+
+func (l *LevelLogger) log(event Event) error {
+	event.Time = time.Now()
+
+	// Skip the frames of this method and of the leveled method that called
+	// it.
+	if pc, ok := GuessCaller(2, 10); ok {
+		if info, ok := GetFuncInfo(pc); ok {
+			event.Info.Source = info.String()
+		}
+	}
+
+	return l.logger.Log(event)
+}
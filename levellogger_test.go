@@ -0,0 +1,71 @@
+package ecslogs
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLevelLogger(t *testing.T) {
+	var events []Event
+
+	log := NewLevelLogger(LoggerFunc(func(e Event) error {
+		events = append(events, e)
+		return nil
+	}))
+
+	now := time.Now()
+	log.Warn("hello", "world")
+	log.Errorf("%d errors", 2)
+
+	if len(events) != 2 {
+		t.Fatal("invalid number of events:", len(events))
+	}
+
+	tests := []struct {
+		level   Level
+		message string
+	}{
+		{WARN, "hello world"},
+		{ERROR, "2 errors"},
+	}
+
+	for i, test := range tests {
+		e := events[i]
+
+		if e.Level != test.level {
+			t.Errorf("event#%d: invalid level: %s", i, e.Level)
+		}
+
+		if e.Message != test.message {
+			t.Errorf("event#%d: invalid message: %q", i, e.Message)
+		}
+
+		if e.Time.Before(now) {
+			t.Errorf("event#%d: invalid time: %s", i, e.Time)
+		}
+
+		if !strings.Contains(e.Info.Source, "levellogger_test.go:") || !strings.HasSuffix(e.Info.Source, ":TestLevelLogger") {
+			t.Errorf("event#%d: invalid source: %s", i, e.Info.Source)
+		}
+	}
+}
+
+func TestLevelLoggerWith(t *testing.T) {
+	var event Event
+
+	log := NewLevelLogger(LoggerFunc(func(e Event) error {
+		event = e
+		return nil
+	})).With(EventData{"a": 1})
+
+	log.Info("hello")
+
+	if s := event.Data.String(); s != `{"a":1}` {
+		t.Error("invalid event data:", s)
+	}
+
+	if !strings.HasSuffix(event.Info.Source, ":TestLevelLoggerWith") {
+		t.Error("invalid source:", event.Info.Source)
+	}
+}
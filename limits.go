@@ -0,0 +1,387 @@
+package ecslogs
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// MaxCloudWatchEventSize is the maximum size of an event accepted by
+// CloudWatch Logs.
+const MaxCloudWatchEventSize = 256 * 1024
+
+// Limits bounds the size and shape of events, the zero value means no
+// limits. Fields that get cut or dropped are recorded in the Truncated and
+// Dropped lists of EventInfo.
+type Limits struct {
+	// Maximum size of an encoded event in bytes, data fields are dropped,
+	// largest first, then error stacks, then the message and the strings of
+	// the event info are truncated until the event fits. As a last resort
+	// the errors and source are dropped.
+	MaxEventSize int
+
+	// Maximum number of top-level data fields.
+	MaxFields int
+
+	// Maximum nesting depth of data values, maps and slices nested deeper
+	// are replaced with the truncation marker.
+	MaxDepth int
+
+	// Maximum length of the message, data strings, source and error strings
+	// in bytes.
+	MaxStringLen int
+
+	// Marker appended to truncated strings, defaults to "...".
+	Marker string
+}
+
+var DefaultLimits = Limits{
+	MaxEventSize: MaxCloudWatchEventSize,
+}
+
+func (l Limits) enabled() bool {
+	return l.MaxEventSize > 0 || l.MaxFields > 0 || l.MaxDepth > 0 || l.MaxStringLen > 0
+}
+
+func (l Limits) marker() string {
+	if len(l.Marker) == 0 {
+		return "..."
+	}
+	return l.Marker
+}
+
+func (l Limits) apply(event Event) Event {
+	if l.MaxStringLen > 0 {
+		if s, cut := truncateString(event.Message, l.MaxStringLen, l.marker()); cut {
+			event.Message = s
+			event.Info.Truncated = append(event.Info.Truncated, "message")
+		}
+		event.Info = l.limitInfo(event.Info, l.MaxStringLen)
+	}
+
+	if event.Data == nil || (l.MaxFields <= 0 && l.MaxDepth <= 0 && l.MaxStringLen <= 0) {
+		return event
+	}
+
+	data := make(EventData, len(event.Data))
+
+	for i, k := range sortedKeys(event.Data) {
+		if l.MaxFields > 0 && i >= l.MaxFields {
+			event.Info.Dropped = append(event.Info.Dropped, "data."+k)
+			continue
+		}
+		data[k] = l.limitValue(&event.Info, "data."+k, event.Data[k], 1)
+	}
+
+	event.Data = data
+	return event
+}
+
+// limitInfo truncates the source and error strings of info to n bytes.
+func (l Limits) limitInfo(info EventInfo, n int) EventInfo {
+	cut := func(path string, s string) string {
+		if t, ok := truncateString(s, n, l.marker()); ok {
+			if !containsString(info.Truncated, path) {
+				info.Truncated = append(info.Truncated, path)
+			}
+			return t
+		}
+		return s
+	}
+
+	info.Truncated = append([]string{}, info.Truncated...)
+	info.Source = cut("info.source", info.Source)
+
+	if len(info.Errors) != 0 {
+		errors := make([]EventError, len(info.Errors))
+
+		for i, e := range info.Errors {
+			path := "info.errors." + strconv.Itoa(i)
+			e.Error = cut(path+".error", e.Error)
+			e.Path = cut(path+".path", e.Path)
+
+			if len(e.Causes) != 0 {
+				causes := make([]ErrorCause, len(e.Causes))
+
+				for j, c := range e.Causes {
+					c.Error = cut(path+".causes."+strconv.Itoa(j)+".error", c.Error)
+					causes[j] = c
+				}
+
+				e.Causes = causes
+			}
+
+			errors[i] = e
+		}
+
+		info.Errors = errors
+	}
+
+	return info
+}
+
+// maxInfoStringLen returns the length of the longest string truncated by
+// limitInfo.
+func maxInfoStringLen(info EventInfo) int {
+	n := len(info.Source)
+
+	for _, e := range info.Errors {
+		for _, s := range []string{e.Error, e.Path} {
+			if len(s) > n {
+				n = len(s)
+			}
+		}
+		for _, c := range e.Causes {
+			if len(c.Error) > n {
+				n = len(c.Error)
+			}
+		}
+	}
+
+	return n
+}
+
+func (l Limits) limitValue(info *EventInfo, path string, v interface{}, depth int) interface{} {
+	switch x := v.(type) {
+	case string:
+		if l.MaxStringLen > 0 {
+			if s, cut := truncateString(x, l.MaxStringLen, l.marker()); cut {
+				info.Truncated = append(info.Truncated, path)
+				return s
+			}
+		}
+		return x
+
+	case EventData:
+		if m, ok := l.limitMap(info, path, x, depth).(map[string]interface{}); ok {
+			return EventData(m)
+		}
+		return l.marker()
+
+	case map[string]interface{}:
+		return l.limitMap(info, path, x, depth)
+
+	case []interface{}:
+		if l.MaxDepth > 0 && depth >= l.MaxDepth {
+			info.Truncated = append(info.Truncated, path)
+			return l.marker()
+		}
+		s := make([]interface{}, len(x))
+		for i, v := range x {
+			s[i] = l.limitValue(info, path+"."+strconv.Itoa(i), v, depth+1)
+		}
+		return s
+
+	default:
+		return v
+	}
+}
+
+func (l Limits) limitMap(info *EventInfo, path string, m map[string]interface{}, depth int) interface{} {
+	if l.MaxDepth > 0 && depth >= l.MaxDepth {
+		info.Truncated = append(info.Truncated, path)
+		return l.marker()
+	}
+
+	c := make(map[string]interface{}, len(m))
+
+	for _, k := range sortedKeys(m) {
+		c[k] = l.limitValue(info, path+"."+k, m[k], depth+1)
+	}
+
+	return c
+}
+
+// shrink removes data fields and error stacks, and truncates the message and
+// info strings until the encoded event fits in MaxEventSize bytes.
+func (l Limits) shrink(b []byte, event Event, flags json.AppendFlags) ([]byte, error) {
+	var err error
+	var start = len(b)
+
+	// fits encodes the event and reports whether shrinking should stop,
+	// either because it fits or because encoding failed.
+	fits := func() bool {
+		b, err = appendEvent(b[:start], event, flags)
+		return err != nil || len(b)-start <= l.MaxEventSize
+	}
+
+	if len(event.Data) != 0 {
+		type field struct {
+			key  string
+			size int
+		}
+
+		fields := make([]field, 0, len(event.Data))
+
+		for k, v := range event.Data {
+			n := len(b)
+			if b, err = appendValue(b, v, flags); err != nil {
+				return b, err
+			}
+			fields = append(fields, field{k, len(b) - n + len(k)})
+			b = b[:n]
+		}
+
+		sort.Slice(fields, func(i, j int) bool {
+			if fields[i].size != fields[j].size {
+				return fields[i].size > fields[j].size
+			}
+			return fields[i].key < fields[j].key
+		})
+
+		data := copyEventData(event.Data)
+		event.Data = data
+
+		for _, f := range fields {
+			delete(data, f.key)
+			event.Info.Dropped = append(event.Info.Dropped, "data."+f.key)
+
+			if fits() {
+				return b, err
+			}
+		}
+	} else if fits() {
+		return b, err
+	}
+
+	if len(event.Info.Errors) != 0 {
+		errors := make([]EventError, len(event.Info.Errors))
+		copy(errors, event.Info.Errors)
+		event.Info.Errors = errors
+
+		for i := range errors {
+			e, path := &errors[i], "info.errors."+strconv.Itoa(i)
+
+			if e.Stack == nil && e.OriginalError == nil && len(e.Causes) == 0 {
+				continue
+			}
+
+			if e.Stack != nil {
+				event.Info.Dropped = append(event.Info.Dropped, path+".stack")
+			}
+			if e.OriginalError != nil {
+				event.Info.Dropped = append(event.Info.Dropped, path+".origError")
+			}
+			if len(e.Causes) != 0 {
+				event.Info.Dropped = append(event.Info.Dropped, path+".causes")
+			}
+
+			e.Stack, e.OriginalError, e.Causes = nil, nil, nil
+
+			if fits() {
+				return b, err
+			}
+		}
+	}
+
+	// Info strings may be truncated to nothing below, dropping them is
+	// decided on their original values.
+	source := event.Info.Source
+
+	if hi := maxInfoStringLen(event.Info); hi != 0 || len(event.Message) != 0 {
+		// The message and info strings are truncated to the same length.
+		// They may be escaped when encoded so the number of bytes to remove
+		// isn't known upfront, the longest length that fits is found with a
+		// binary search.
+		message, info, lo := event.Message, event.Info, 0
+
+		if len(message) > hi {
+			hi = len(message)
+		}
+
+		truncate := func(n int) {
+			var cut bool
+			event.Info = l.limitInfo(info, n)
+
+			if event.Message, cut = truncateString(message, n, l.marker()); cut && !containsString(event.Info.Truncated, "message") {
+				event.Info.Truncated = append(event.Info.Truncated, "message")
+			}
+		}
+
+		for lo < hi {
+			n := (lo + hi + 1) / 2
+			truncate(n)
+
+			if b, err = appendEvent(b[:start], event, flags); err != nil {
+				return b, err
+			}
+
+			if len(b)-start <= l.MaxEventSize {
+				lo = n
+			} else {
+				hi = n - 1
+			}
+		}
+
+		if truncate(lo); fits() {
+			return b, err
+		}
+	}
+
+	if len(event.Info.Errors) != 0 || len(source) != 0 {
+		if len(event.Info.Errors) != 0 {
+			event.Info.Dropped = append(event.Info.Dropped, "info.errors")
+		}
+		if len(source) != 0 {
+			event.Info.Dropped = append(event.Info.Dropped, "info.source")
+		}
+		truncated := make([]string, 0, len(event.Info.Truncated))
+
+		for _, path := range event.Info.Truncated {
+			if !strings.HasPrefix(path, "info.errors.") && path != "info.source" {
+				truncated = append(truncated, path)
+			}
+		}
+
+		event.Info.Errors, event.Info.Source, event.Info.Truncated = nil, "", truncated
+		fits()
+	}
+
+	return b, err
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+func truncateString(s string, n int, marker string) (string, bool) {
+	if len(s) <= n {
+		return s, false
+	}
+
+	if len(marker) > n {
+		// The result must not be longer than n even with a long marker.
+		m := n
+		for m > 0 && !utf8.RuneStart(marker[m]) {
+			m--
+		}
+		marker = marker[:m]
+	}
+
+	n -= len(marker)
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n] + marker, true
+}
@@ -0,0 +1,128 @@
+package ecslogs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		limits Limits
+		event  Event
+		s      string
+	}{
+		{
+			limits: Limits{MaxStringLen: 8},
+			event: Event{
+				Level:   INFO,
+				Message: "Hello World!",
+				Data:    EventData{"short": "abc", "long": "ééééé", "nested": EventData{"key": "0123456789"}},
+			},
+			s: `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{"truncated":["message","data.long","data.nested.key"]},"data":{"long":"éé...","nested":{"key":"01234..."},"short":"abc"},"message":"Hello..."}`,
+		},
+		{
+			limits: Limits{MaxStringLen: 2},
+			event: Event{
+				Level:   INFO,
+				Message: "Hello",
+			},
+			s: `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{"truncated":["message"]},"data":null,"message":".."}`,
+		},
+		{
+			limits: Limits{MaxFields: 2, Marker: "~"},
+			event: Event{
+				Level: INFO,
+				Data:  EventData{"c": 3, "b": 2, "a": 1},
+			},
+			s: `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{"dropped":["data.c"]},"data":{"a":1,"b":2},"message":""}`,
+		},
+		{
+			limits: Limits{MaxDepth: 2},
+			event: Event{
+				Level: INFO,
+				Data:  EventData{"a": EventData{"b": EventData{"c": 1}, "d": []interface{}{1}, "e": 2}},
+			},
+			s: `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{"truncated":["data.a.b","data.a.d"]},"data":{"a":{"b":"...","d":"...","e":2}},"message":""}`,
+		},
+		{
+			limits: Limits{MaxEventSize: 150},
+			event: Event{
+				Level:   INFO,
+				Data:    EventData{"small": 1, "large": strings.Repeat("-", 100)},
+				Message: "Hello World!",
+			},
+			s: `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{"dropped":["data.large"]},"data":{"small":1},"message":"Hello World!"}`,
+		},
+		{
+			limits: Limits{MaxEventSize: 150},
+			event: Event{
+				Level:   INFO,
+				Data:    EventData{"small": 1},
+				Message: strings.Repeat("<>", 100),
+			},
+			s: `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{"truncated":["message"],"dropped":["data.small"]},"data":{},"message":"\u003c\u003e\u003c..."}`,
+		},
+		{
+			limits: Limits{MaxEventSize: 150},
+			event: Event{
+				Level:   ERROR,
+				Data:    EventData{},
+				Message: strings.Repeat("x", 200),
+			},
+			s: `{"level":"ERROR","time":"0001-01-01T00:00:00Z","info":{"truncated":["message"]},"data":{},"message":"` + strings.Repeat("x", 44) + `..."}`,
+		},
+		{
+			limits: Limits{MaxStringLen: 8},
+			event: Event{
+				Level: ERROR,
+				Info: EventInfo{
+					Source: "main.go:42:main",
+					Errors: []EventError{{Type: "T", Error: "0123456789", Causes: []ErrorCause{{Error: "abcdefghij"}}}},
+				},
+				Data: EventData{},
+			},
+			s: `{"level":"ERROR","time":"0001-01-01T00:00:00Z","info":{"source":"main....","errors":[{"type":"T","error":"01234...","causes":[{"error":"abcde..."}]}],"truncated":["info.source","info.errors.0.error","info.errors.0.causes.0.error"]},"data":{},"message":""}`,
+		},
+		{
+			limits: Limits{MaxEventSize: 200},
+			event: Event{
+				Level: ERROR,
+				Info: EventInfo{
+					Source: strings.Repeat("s", 300),
+					Errors: []EventError{{Error: "EOF", Stack: []FuncInfo{{File: "main.go", Func: "main", Line: 1}}}},
+				},
+				Data: EventData{},
+			},
+			s: `{"level":"ERROR","time":"0001-01-01T00:00:00Z","info":{"source":"` + strings.Repeat("s", 17) + `...","errors":[{"error":"EOF"}],"truncated":["info.source"],"dropped":["info.errors.0.stack"]},"data":{},"message":""}`,
+		},
+		{
+			limits: Limits{MaxEventSize: 140},
+			event: Event{
+				Level: ERROR,
+				Info:  EventInfo{Host: "host", Source: "main.go:42:main", Errors: []EventError{{Error: "EOF"}}},
+				Data:  EventData{},
+			},
+			s: `{"level":"ERROR","time":"0001-01-01T00:00:00Z","info":{"host":"host","dropped":["info.errors","info.source"]},"data":{},"message":""}`,
+		},
+	}
+
+	for i, test := range tests {
+		buf := &bytes.Buffer{}
+		log := NewLoggerWith(Config{Output: buf, Limits: test.limits})
+
+		if err := log.Log(test.event); err != nil {
+			t.Errorf("test#%d: %s", i, err)
+		}
+
+		s := strings.TrimSpace(buf.String())
+
+		if s != test.s {
+			t.Errorf("test#%d:\n- expected: %s\n- found:    %s", i, test.s, s)
+		}
+
+		if max := test.limits.MaxEventSize; max != 0 && len(s) > max {
+			t.Errorf("test#%d: event too large: %d > %d", i, len(s), max)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package log_ecslogs
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"log"
 	"strconv"
@@ -13,6 +14,23 @@ const (
 	DefaultLevel = ecslogs.INFO
 )
 
+type Config struct {
+	Level  ecslogs.Level
+	Output io.Writer
+	Limits ecslogs.Limits
+	Hooks  []ecslogs.Hook
+
+	// ExitOnLevel, ExitCode and Exit configure the exit policy of the
+	// underlying ecslogs logger.
+	ExitOnLevel ecslogs.Level
+	ExitCode    int
+	Exit        func(int)
+
+	// Context carries fields set with ecslogs.ContextWithFields and a trace
+	// whose identifiers are added to every event.
+	Context context.Context
+}
+
 type Handler interface {
 	HandleEntry(Entry) error
 }
@@ -44,9 +62,32 @@ func NewHandler(out io.Writer) Handler {
 }
 
 func NewHandlerWithLevel(level ecslogs.Level, out io.Writer) Handler {
-	logger := ecslogs.NewLogger(out)
+	return NewHandlerWith(Config{Level: level, Output: out})
+}
+
+func NewHandlerWith(c Config) Handler {
+	if c.Level == ecslogs.NONE {
+		c.Level = DefaultLevel
+	}
+
+	logger := ecslogs.NewLoggerWith(ecslogs.Config{
+		Output: c.Output,
+		Limits: c.Limits,
+		Hooks:  c.Hooks,
+
+		ExitOnLevel: c.ExitOnLevel,
+		ExitCode:    c.ExitCode,
+		Exit:        c.Exit,
+	})
+
+	if c.Context != nil {
+		return HandlerFunc(func(entry Entry) error {
+			return ecslogs.LogContext(c.Context, logger, makeEvent(c.Level, entry))
+		})
+	}
+
 	return HandlerFunc(func(entry Entry) error {
-		return logger.Log(makeEvent(level, entry))
+		return logger.Log(makeEvent(c.Level, entry))
 	})
 }
 
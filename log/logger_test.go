@@ -2,10 +2,13 @@ package log_ecslogs
 
 import (
 	"bytes"
+	"context"
 	"log"
 	"reflect"
 	"testing"
 	"time"
+
+	"github.com/segmentio/ecs-logs-go"
 )
 
 func TestLineWriter(t *testing.T) {
@@ -159,3 +162,41 @@ func TestLogger(t *testing.T) {
 		}
 	}
 }
+
+func TestHandlerContext(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	handler := NewHandlerWith(Config{
+		Level:  ecslogs.WARN,
+		Output: buffer,
+		Context: ecslogs.ContextWithTrace(
+			ecslogs.ContextWithFields(context.Background(), ecslogs.EventData{"requestId": "1234"}),
+			ecslogs.TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736"},
+		),
+	})
+
+	logger := log.New(NewWriter("test ", 0, handler), "test ", 0)
+	logger.Println("A")
+
+	const expected = `{"level":"WARN","time":"0001-01-01T00:00:00Z","info":{},"data":{"prefix":"test","requestId":"1234","traceId":"4bf92f3577b34da6a3ce929d0e0e4736"},"message":"A"}
+`
+
+	if s := buffer.String(); s != expected {
+		t.Errorf("invalid output:\n- expected: %v\n- found:    %v", expected, s)
+	}
+}
+
+func TestHandlerDefaultLevel(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	handler := NewHandlerWith(Config{Output: buffer})
+
+	log.New(NewWriter("", 0, handler), "", 0).Println("A")
+
+	e, err := ecslogs.NewReader(buffer).ReadEvent()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Level != DefaultLevel {
+		t.Error("invalid level:", e.Level)
+	}
+}
@@ -1,6 +1,7 @@
 package ecslogs
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -12,21 +13,163 @@ type Logger interface {
 	Log(Event) error
 }
 
+type Flusher interface {
+	Flush(context.Context) error
+}
+
+// flushLogger flushes logger if it implements Flusher.
+func flushLogger(ctx context.Context, logger Logger) error {
+	if f, ok := logger.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
 type LoggerFunc func(Event) error
 
 func (f LoggerFunc) Log(e Event) error {
 	return f(e)
 }
 
+// Hook is called on events before they are encoded, it may modify the event
+// and returns false to drop it. Event data may be shared with the code that
+// logged the event so hooks that change it should set a modified copy.
+type Hook func(*Event) (keep bool)
+
+type Config struct {
+	Output io.Writer
+	Limits Limits
+
+	// When ProcessInfo is true the host, process, user, group and container
+	// IDs are added to the info of events that don't already have them.
+	ProcessInfo bool
+
+	// Events at StackLevel or more severe get the stack of the goroutine
+	// that logged them set on errors that don't carry a stack already, or on
+	// an error with only the stack when they have none.
+	StackLevel Level
+
+	// Hooks are applied in order to every event before it is encoded.
+	Hooks []Hook
+
+	// Logging an event at ExitOnLevel or more severe makes the program exit
+	// with ExitCode (1 by default) once the event was written and the output
+	// flushed. Exit defaults to os.Exit and can be replaced in tests.
+	ExitOnLevel Level
+	ExitCode    int
+	Exit        func(int)
+}
+
 func NewLogger(w io.Writer) Logger {
-	if w == nil {
-		w = os.Stderr
+	return NewLoggerWith(Config{Output: w})
+}
+
+func NewLoggerWith(c Config) Logger {
+	if c.Output == nil {
+		c.Output = os.Stderr
+	}
+
+	if c.ExitCode == 0 {
+		c.ExitCode = 1
+	}
+
+	if c.Exit == nil {
+		c.Exit = os.Exit
+	}
+
+	l := &logger{
+		out:        c.Output,
+		enc:        NewEncoder(c.Output),
+		stackLevel: c.StackLevel,
+		hooks:      append([]Hook{}, c.Hooks...),
+		exitLevel:  c.ExitOnLevel,
+		exitCode:   c.ExitCode,
+		exit:       c.Exit,
+	}
+
+	l.enc.SetLimits(c.Limits)
+
+	if c.ProcessInfo {
+		info := ProcessInfo()
+		l.info = &info
+	}
+
+	return l
+}
+
+type logger struct {
+	out        io.Writer
+	enc        *Encoder
+	info       *EventInfo
+	stackLevel Level
+	hooks      []Hook
+	exitLevel  Level
+	exitCode   int
+	exit       func(int)
+}
+
+func (l *logger) Log(event Event) error {
+	defer l.exitOn(event.Level)
+
+	if !l.applyHooks(&event) {
+		return nil
+	}
+	return encode(l.enc, l.prepare(event))
+}
+
+func (l *logger) logBound(event Event, fields *boundFields) error {
+	if l.enc.limits.enabled() || len(l.hooks) != 0 || ShouldExit(event.Level, l.exitLevel) {
+		// Limits and hooks need to see all the data fields so the
+		// pre-encoded ones can't be used.
+		event.Data = copyEventData(fields.data, event.Data)
+		return l.Log(event)
+	}
+
+	event = l.prepare(event)
+	err := l.enc.encodeBound(event, fields.encoded)
+
+	switch err.(type) {
+	case *json.UnsupportedTypeError, *json.UnsupportedValueError, *json.MarshalerError:
+		event.Data = copyEventData(fields.data, event.Data)
+		err = encode(l.enc, event)
+	}
+
+	return err
+}
+
+// Flush flushes the output of the logger, see FlushWriter.
+func (l *logger) Flush(ctx context.Context) error {
+	return FlushWriter(ctx, l.out)
+}
+
+// exitOn terminates the program if level matches the exit policy, the level
+// of the event as it was logged is used even if hooks changed or dropped it.
+func (l *logger) exitOn(level Level) {
+	if ShouldExit(level, l.exitLevel) {
+		ExitAfterFlush(l.out, l.exit, l.exitCode)
+	}
+}
+
+func (l *logger) applyHooks(event *Event) bool {
+	for _, hook := range l.hooks {
+		if !hook(event) {
+			return false
+		}
+	}
+	return true
+}
+
+func (l *logger) prepare(event Event) Event {
+	if l.info != nil {
+		event.Info = mergeEventInfo(event.Info, *l.info)
+	}
+	if event.Level != NONE && event.Level <= l.stackLevel {
+		event.Info.Errors = withStack(event.Info.Errors)
 	}
-	enc := json.NewEncoder(w)
-	return LoggerFunc(func(event Event) error { return encode(enc, event) })
+	return event
 }
 
-func encode(enc *json.Encoder, event Event) (err error) {
+func encode(enc *Encoder, event Event) (err error) {
 	if err = enc.Encode(event); err == nil {
 		return
 	}
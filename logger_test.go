@@ -2,6 +2,7 @@ package ecslogs
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 )
 
@@ -31,3 +32,38 @@ func TestLoggerLog(t *testing.T) {
 		}
 	}
 }
+
+func TestLoggerHooks(t *testing.T) {
+	var calls []string
+
+	b := &bytes.Buffer{}
+	log := NewLoggerWith(Config{
+		Output: b,
+		Hooks: []Hook{
+			func(e *Event) bool {
+				calls = append(calls, "drop")
+				return e.Level != DEBUG
+			},
+			func(e *Event) bool {
+				calls = append(calls, "rename")
+				e.Data = EventData{"user": e.Data["usr"], "service": e.Data["service"]}
+				return true
+			},
+		},
+	})
+	log = With(log, EventData{"service": "api"})
+
+	log.Log(Event{Level: DEBUG, Message: "A"})
+	log.Log(Event{Level: INFO, Message: "B", Data: EventData{"usr": "me"}})
+
+	const expected = `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{},"data":{"service":"api","user":"me"},"message":"B"}
+`
+
+	if s := b.String(); s != expected {
+		t.Errorf("\n- expected: %s\n- found:    %s", expected, s)
+	}
+
+	if s := strings.Join(calls, ","); s != "drop,drop,rename" {
+		t.Error("invalid hook calls:", s)
+	}
+}
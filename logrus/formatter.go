@@ -1,7 +1,18 @@
+// Package logrus_ecslogs provides a logrus formatter producing ecs-logs
+// events.
+//
+// Logrus calls formatters while it holds the lock of the logger, so entries at
+// FatalLevel only exit through logrus.Fatal and the ExitFunc of the logger,
+// which can be set with ExitFunc to flush the output first. Entries at other
+// levels matching the exit policy of the formatter are written and flushed
+// before the formatter terminates the program, without running the exit
+// handlers registered with logrus.
 package logrus_ecslogs
 
 import (
 	"bytes"
+	"io"
+	"os"
 
 	"github.com/segmentio/ecs-logs-go"
 	"github.com/sirupsen/logrus"
@@ -10,6 +21,17 @@ import (
 type Config struct {
 	Depth    int
 	FuncInfo func(uintptr) (ecslogs.FuncInfo, bool)
+	Limits   ecslogs.Limits
+	Hooks    []ecslogs.Hook
+
+	// Formatting an entry at ExitOnLevel or more severe makes the program
+	// exit with ExitCode (1 by default) once the entry was written to the
+	// output of its logger, except for entries at FatalLevel which exit
+	// through logrus.Fatal. Exit defaults to os.Exit, it is called while
+	// logrus holds the lock of the logger and must not log with it.
+	ExitOnLevel ecslogs.Level
+	ExitCode    int
+	Exit        func(int)
 }
 
 func NewFormatter() logrus.Formatter {
@@ -36,13 +58,64 @@ func (f formatter) Format(entry *logrus.Entry) (b []byte, err error) {
 	buf := &bytes.Buffer{}
 	buf.Grow(1024)
 
-	if err = ecslogs.NewLogger(buf).Log(makeEvent(entry, source)); err == nil {
+	logger := ecslogs.NewLoggerWith(ecslogs.Config{
+		Output: buf,
+		Limits: f.Limits,
+		Hooks:  f.Hooks,
+	})
+
+	if err = logger.Log(makeEvent(entry, source)); err == nil {
 		b = buf.Bytes()
 	}
 
+	if err == nil && entry.Level != logrus.FatalLevel && ecslogs.ShouldExit(makeLevel(entry.Level), f.ExitOnLevel) {
+		if f.exit(entry, b) {
+			b = nil // already written, only reached when Exit returns
+		}
+	}
+
+	return
+}
+
+// exit writes b and terminates the program, logrus only writes the output of
+// formatters after they return so the entry would be lost otherwise.
+func (f formatter) exit(entry *logrus.Entry, b []byte) (written bool) {
+	code, exit := f.ExitCode, f.Exit
+
+	if code == 0 {
+		code = 1
+	}
+
+	if exit == nil {
+		exit = os.Exit
+	}
+
+	var out io.Writer
+
+	if entry.Logger != nil && entry.Logger.Out != nil {
+		out = entry.Logger.Out
+		out.Write(b)
+		written = true
+	}
+
+	ecslogs.ExitAfterFlush(out, exit, code)
 	return
 }
 
+// ExitFunc returns a function to set as ExitFunc of a logrus logger, which
+// flushes out before calling exit (os.Exit by default):
+//
+//	log.ExitFunc = logrus_ecslogs.ExitFunc(log.Out, nil)
+func ExitFunc(out io.Writer, exit func(int)) func(int) {
+	if exit == nil {
+		exit = os.Exit
+	}
+
+	return func(code int) {
+		ecslogs.ExitAfterFlush(out, exit, code)
+	}
+}
+
 func makeEvent(entry *logrus.Entry, source string) ecslogs.Event {
 	return ecslogs.Event{
 		Level:   makeLevel(entry.Level),
@@ -71,6 +144,14 @@ func makeEventData(entry *logrus.Entry) ecslogs.EventData {
 		}
 	}
 
+	if entry.Context != nil {
+		for k, v := range ecslogs.ContextData(entry.Context) {
+			if _, ok := data[k]; !ok {
+				data[k] = v
+			}
+		}
+	}
+
 	return data
 }
 
@@ -2,6 +2,7 @@ package logrus_ecslogs
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"strings"
 	"testing"
@@ -42,3 +43,131 @@ func testFuncInfo(pc uintptr) (info ecslogs.FuncInfo, ok bool) {
 	info.Line = 42
 	return
 }
+
+func TestFormatterHooks(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := &logrus.Logger{
+		Out:   buf,
+		Level: logrus.DebugLevel,
+		Formatter: NewFormatterWith(Config{
+			Hooks: []ecslogs.Hook{
+				func(e *ecslogs.Event) bool { return e.Level != ecslogs.DEBUG },
+				func(e *ecslogs.Event) bool { e.Message = strings.ToUpper(e.Message); return true },
+			},
+		}),
+	}
+
+	log.Debug("dropped")
+	log.Info("hello")
+
+	s := strings.TrimSpace(buf.String())
+
+	if !strings.HasSuffix(s, `"message":"HELLO"}`) || strings.Count(s, "\n") != 0 {
+		t.Error("logrus formatter failed:", s)
+	}
+}
+
+type flushBuffer struct {
+	bytes.Buffer
+	flushed int
+}
+
+func (b *flushBuffer) Flush() error {
+	b.flushed = b.Len()
+	return nil
+}
+
+func TestExitFunc(t *testing.T) {
+	buf := &flushBuffer{}
+	codes := []int{}
+	log := &logrus.Logger{
+		Out:       buf,
+		Level:     logrus.DebugLevel,
+		Formatter: NewFormatter(),
+	}
+	log.ExitFunc = ExitFunc(log.Out, func(code int) {
+		// Exit handlers must be able to log without deadlocking.
+		log.Info("exiting")
+		codes = append(codes, code)
+	})
+
+	log.Error("A")
+	log.Fatal("B")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if len(lines) != 3 || !strings.HasSuffix(lines[1], `"message":"B"}`) {
+		t.Error("logrus formatter failed:", lines)
+	}
+
+	if buf.flushed != len(lines[0])+len(lines[1])+2 {
+		t.Error("the output wasn't flushed before exiting:", buf.flushed)
+	}
+
+	if len(codes) != 1 || codes[0] != 1 {
+		t.Error("invalid exit codes:", codes)
+	}
+}
+
+func TestFormatterExit(t *testing.T) {
+	buf := &flushBuffer{}
+	codes := []int{}
+	fatal := []int{}
+	log := &logrus.Logger{
+		Out:   buf,
+		Level: logrus.DebugLevel,
+		Formatter: NewFormatterWith(Config{
+			ExitOnLevel: ecslogs.ERROR,
+			ExitCode:    3,
+			Exit:        func(code int) { codes = append(codes, code) },
+		}),
+		ExitFunc: func(code int) { fatal = append(fatal, code) },
+	}
+
+	log.Warn("A")
+	log.Error("B")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if len(lines) != 2 || !strings.HasSuffix(lines[1], `"message":"B"}`) {
+		t.Error("logrus formatter failed:", lines)
+	}
+
+	if buf.flushed != buf.Len() {
+		t.Error("the output wasn't flushed before exiting:", buf.flushed)
+	}
+
+	if len(codes) != 1 || codes[0] != 3 {
+		t.Error("invalid exit codes:", codes)
+	}
+
+	// Fatal entries exit through logrus only.
+	log.Fatal("C")
+
+	if len(codes) != 1 || len(fatal) != 1 || fatal[0] != 1 {
+		t.Error("invalid exit codes after a fatal entry:", codes, fatal)
+	}
+}
+
+func TestFormatterContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := &logrus.Logger{
+		Out:       buf,
+		Level:     logrus.DebugLevel,
+		Formatter: NewFormatter(),
+	}
+
+	ctx := ecslogs.ContextWithFields(context.Background(), ecslogs.EventData{"requestId": "1234", "hello": "context"})
+	ctx = ecslogs.ContextWithTrace(ctx, ecslogs.TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736"})
+
+	log.
+		WithContext(ctx).
+		WithField("hello", "world").
+		Info("hello")
+
+	s := strings.TrimSpace(buf.String())
+
+	if !strings.HasSuffix(s, `"data":{"hello":"world","requestId":"1234","traceId":"4bf92f3577b34da6a3ce929d0e0e4736"},"message":"hello"}`) {
+		t.Error("logrus formatter failed:", s)
+	}
+}
@@ -0,0 +1,224 @@
+package ecslogs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// ECSMetadata is the subset of the ECS task and container metadata that is
+// added to events.
+type ECSMetadata struct {
+	Cluster          string
+	TaskARN          string
+	Family           string
+	Revision         string
+	AvailabilityZone string
+	ContainerName    string
+	ContainerID      string
+}
+
+func (m ECSMetadata) data() EventData {
+	data := EventData{}
+
+	for k, v := range map[string]string{
+		"cluster":          m.Cluster,
+		"taskArn":          m.TaskARN,
+		"family":           m.Family,
+		"revision":         m.Revision,
+		"availabilityZone": m.AvailabilityZone,
+		"container":        m.ContainerName,
+	} {
+		if len(v) != 0 {
+			data[k] = v
+		}
+	}
+
+	return data
+}
+
+// ECSMetadataURI returns the base URI of the container metadata endpoint, or
+// an empty string when the program isn't running on ECS.
+func ECSMetadataURI() string {
+	if uri := os.Getenv("ECS_CONTAINER_METADATA_URI_V4"); len(uri) != 0 {
+		return uri
+	}
+	return os.Getenv("ECS_CONTAINER_METADATA_URI")
+}
+
+func FetchECSMetadata(ctx context.Context, client *http.Client, uri string) (m ECSMetadata, err error) {
+	var task struct {
+		Cluster          string
+		TaskARN          string
+		Family           string
+		Revision         string
+		AvailabilityZone string
+	}
+
+	var container struct {
+		DockerId string
+		Name     string
+	}
+
+	uri = strings.TrimSuffix(uri, "/")
+
+	if err = fetchJSON(ctx, client, uri+"/task", &task); err != nil {
+		return
+	}
+
+	if err = fetchJSON(ctx, client, uri, &container); err != nil {
+		return
+	}
+
+	m = ECSMetadata{
+		Cluster:          task.Cluster,
+		TaskARN:          task.TaskARN,
+		Family:           task.Family,
+		Revision:         task.Revision,
+		AvailabilityZone: task.AvailabilityZone,
+		ContainerName:    container.Name,
+		ContainerID:      container.DockerId,
+	}
+	return
+}
+
+func fetchJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, res.Status)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+type ECSMetadataConfig struct {
+	// URI of the container metadata endpoint, defaults to ECSMetadataURI().
+	URI    string
+	Client *http.Client
+
+	// Timeout of requests to the metadata endpoint, and time to wait before
+	// trying again after a failure.
+	Timeout       time.Duration
+	RetryInterval time.Duration
+
+	// Key of the data field that the metadata are added to, defaults to
+	// "ecs".
+	Key string
+}
+
+// ECSMetadataLogger is a logger adding the ECS task and container metadata
+// to events.
+type ECSMetadataLogger struct {
+	logger Logger
+	config ECSMetadataConfig
+	meta   atomic.Value // *ecsMetadataCache
+	ready  chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewECSMetadataLogger returns a logger which adds the ECS task and
+// container metadata to events. The metadata are fetched in the background
+// starting when the logger is created, events are passed through unchanged
+// until they are available or when the program doesn't run on ECS.
+func NewECSMetadataLogger(logger Logger, c ECSMetadataConfig) *ECSMetadataLogger {
+	if len(c.URI) == 0 {
+		c.URI = ECSMetadataURI()
+	}
+
+	if c.Client == nil {
+		c.Client = http.DefaultClient
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = 2 * time.Second
+	}
+
+	if c.RetryInterval <= 0 {
+		c.RetryInterval = time.Minute
+	}
+
+	if len(c.Key) == 0 {
+		c.Key = "ecs"
+	}
+
+	m := &ECSMetadataLogger{
+		logger: logger,
+		config: c,
+		ready:  make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	if len(c.URI) != 0 {
+		go m.fetch()
+	}
+
+	return m
+}
+
+func (m *ECSMetadataLogger) Log(event Event) error {
+	if meta, _ := m.meta.Load().(*ecsMetadataCache); meta != nil {
+		if _, exists := event.Data[m.config.Key]; !exists {
+			event.Data = copyEventData(event.Data, EventData{m.config.Key: meta.data})
+		}
+		if len(event.Info.ID) == 0 {
+			event.Info.ID = meta.id
+		}
+	}
+	return m.logger.Log(event)
+}
+
+func (m *ECSMetadataLogger) Flush(ctx context.Context) error {
+	return flushLogger(ctx, m.logger)
+}
+
+// Close stops fetching the metadata if they weren't available yet.
+func (m *ECSMetadataLogger) Close() error {
+	m.once.Do(func() { close(m.done) })
+	return nil
+}
+
+type ecsMetadataCache struct {
+	data EventData
+	id   string
+}
+
+func (m *ECSMetadataLogger) fetch() {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), m.config.Timeout)
+		meta, err := FetchECSMetadata(ctx, m.config.Client, m.config.URI)
+		cancel()
+
+		if err == nil {
+			m.meta.Store(&ecsMetadataCache{data: meta.data(), id: meta.ContainerID})
+			close(m.ready)
+			return
+		}
+
+		timer := time.NewTimer(m.config.RetryInterval)
+
+		select {
+		case <-timer.C:
+		case <-m.done:
+			timer.Stop()
+			return
+		}
+	}
+}
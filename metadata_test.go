@@ -0,0 +1,111 @@
+package ecslogs
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestECSMetadataLogger(t *testing.T) {
+	var event Event
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v4/abc":
+			w.Write([]byte(`{"DockerId":"abc","Name":"web","Image":"nginx"}`))
+		case "/v4/abc/task":
+			w.Write([]byte(`{
+				"Cluster": "prod",
+				"TaskARN": "arn:aws:ecs:us-west-2:123456789012:task/prod/abc",
+				"Family": "web",
+				"Revision": "42",
+				"AvailabilityZone": "us-west-2a"
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	log := NewECSMetadataLogger(LoggerFunc(func(e Event) error {
+		event = e
+		return nil
+	}), ECSMetadataConfig{URI: server.URL + "/v4/abc"})
+	defer log.Close()
+
+	select {
+	case <-log.ready:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for the metadata to be fetched")
+	}
+
+	e := Eprint(INFO, "hello")
+	e.Data["hello"] = "world"
+	log.Log(e)
+
+	const expected = `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{"id":"abc"},"data":{"ecs":{"availabilityZone":"us-west-2a","cluster":"prod","container":"web","family":"web","revision":"42","taskArn":"arn:aws:ecs:us-west-2:123456789012:task/prod/abc"},"hello":"world"},"message":"hello"}`
+
+	if s := event.String(); s != expected {
+		t.Errorf("\n- expected: %s\n- found:    %s", expected, s)
+	}
+
+	if _, ok := e.Data["ecs"]; ok {
+		t.Error("the original event data was modified")
+	}
+}
+
+func TestECSMetadataLoggerUnavailable(t *testing.T) {
+	var event Event
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	log := NewECSMetadataLogger(LoggerFunc(func(e Event) error {
+		event = e
+		return nil
+	}), ECSMetadataConfig{URI: server.URL})
+	defer log.Close()
+
+	if err := log.Log(Eprint(INFO, "hello")); err != nil {
+		t.Error(err)
+	}
+
+	if s := event.String(); s != `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{},"data":{},"message":"hello"}` {
+		t.Error("the event was modified:", s)
+	}
+}
+
+func TestECSMetadataLoggerPending(t *testing.T) {
+	var event Event
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	log := NewECSMetadataLogger(LoggerFunc(func(e Event) error {
+		event = e
+		return nil
+	}), ECSMetadataConfig{URI: server.URL})
+	defer log.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- log.Log(Eprint(INFO, "hello")) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("logging was blocked by fetching the metadata")
+	}
+
+	if s := event.String(); s != `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{},"data":{},"message":"hello"}` {
+		t.Error("the event was modified:", s)
+	}
+}
@@ -0,0 +1,246 @@
+package ecslogs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type RateLimiterConfig struct {
+	// Rate is the number of events per second allowed for each key, Burst is
+	// the number of events that can go through at once after a quiet period.
+	Rate  float64
+	Burst int
+
+	// Key returns the key that events are accounted under, defaults to
+	// KeyByMessage.
+	Key func(Event) string
+
+	// Interval is the minimum time between two summaries of dropped events,
+	// they are emitted when the interval has elapsed even if no other events
+	// are logged.
+	Interval time.Duration
+
+	Now func() time.Time
+}
+
+func KeyByLevel(e Event) string {
+	return e.Level.String()
+}
+
+func KeyByMessage(e Event) string {
+	return e.Message
+}
+
+func KeyByData(path string) func(Event) string {
+	return func(e Event) string {
+		if v, ok := e.Data.Get(path); ok {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+}
+
+type RateLimiter struct {
+	logger   Logger
+	rate     float64
+	burst    float64
+	key      func(Event) string
+	interval time.Duration
+	now      func() time.Time
+
+	mutex   sync.Mutex
+	buckets map[string]*tokenBucket
+	dropped map[string]int
+	next    time.Time
+	timer   *time.Timer
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func NewRateLimiter(logger Logger, c RateLimiterConfig) *RateLimiter {
+	if c.Rate <= 0 {
+		c.Rate = 10
+	}
+
+	if c.Burst <= 0 {
+		c.Burst = int(c.Rate)
+	}
+
+	if c.Burst <= 0 {
+		c.Burst = 1
+	}
+
+	if c.Key == nil {
+		c.Key = KeyByMessage
+	}
+
+	if c.Interval <= 0 {
+		c.Interval = 10 * time.Second
+	}
+
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+
+	return &RateLimiter{
+		logger:   logger,
+		rate:     c.Rate,
+		burst:    float64(c.Burst),
+		key:      c.Key,
+		interval: c.Interval,
+		now:      c.Now,
+		buckets:  map[string]*tokenBucket{},
+		dropped:  map[string]int{},
+		next:     c.Now().Add(c.Interval),
+	}
+}
+
+func (r *RateLimiter) Log(event Event) error {
+	key := r.key(event)
+
+	r.mutex.Lock()
+	now := r.now()
+	summary, hasSummary := r.summary(now, false)
+	allow := r.take(key, now)
+
+	if !allow {
+		r.dropped[key]++
+
+		if r.timer == nil {
+			r.timer = r.schedule(r.next.Sub(now))
+		}
+	}
+
+	r.mutex.Unlock()
+
+	var errs MultiError
+
+	if hasSummary {
+		if err := r.logger.Log(summary); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if allow {
+		if err := r.logger.Log(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs.err()
+}
+
+func (r *RateLimiter) Flush(ctx context.Context) error {
+	r.mutex.Lock()
+	summary, hasSummary := r.summary(r.now(), true)
+	r.mutex.Unlock()
+
+	if hasSummary {
+		if err := r.logger.Log(summary); err != nil {
+			return err
+		}
+	}
+
+	if f, ok := r.logger.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+
+	return nil
+}
+
+// schedule returns a timer emitting the summary of dropped events after d, so
+// it isn't held back until the next event is logged.
+func (r *RateLimiter) schedule(d time.Duration) *time.Timer {
+	var timer *time.Timer
+
+	timer = time.AfterFunc(d, func() {
+		r.mutex.Lock()
+
+		if r.timer != timer {
+			r.mutex.Unlock()
+			return
+		}
+
+		summary, hasSummary := r.summary(r.now(), true)
+		r.mutex.Unlock()
+
+		if hasSummary {
+			r.logger.Log(summary)
+		}
+	})
+
+	return timer
+}
+
+func (r *RateLimiter) take(key string, now time.Time) bool {
+	b := r.buckets[key]
+
+	if b == nil {
+		b = &tokenBucket{tokens: r.burst, last: now}
+		r.buckets[key] = b
+	}
+
+	b.refill(now, r.rate, r.burst)
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+func (r *RateLimiter) summary(now time.Time, force bool) (event Event, ok bool) {
+	if !force && now.Before(r.next) {
+		return
+	}
+
+	r.next = now.Add(r.interval)
+
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+
+	// Buckets that have refilled completely are in the same state as new
+	// ones, they are removed so keys that aren't used anymore don't leak.
+	for key, b := range r.buckets {
+		if b.refill(now, r.rate, r.burst); b.tokens >= r.burst {
+			delete(r.buckets, key)
+		}
+	}
+
+	if len(r.dropped) == 0 {
+		return
+	}
+
+	total := 0
+	dropped := make(EventData, len(r.dropped))
+
+	for key, n := range r.dropped {
+		dropped[key] = n
+		total += n
+	}
+
+	r.dropped = map[string]int{}
+
+	event = Eprintf(WARN, "rate limit exceeded, %d events were dropped", total)
+	event.Time = now
+	event.Data["dropped"] = dropped
+	ok = true
+	return
+}
+
+func (b *tokenBucket) refill(now time.Time, rate float64, burst float64) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		if b.tokens += elapsed.Seconds() * rate; b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	b.last = now
+}
@@ -0,0 +1,120 @@
+package ecslogs
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	var events []Event
+
+	now := time.Date(2016, 12, 5, 10, 31, 42, 0, time.UTC)
+	log := NewRateLimiter(LoggerFunc(func(e Event) error {
+		events = append(events, e)
+		return nil
+	}), RateLimiterConfig{
+		Rate:     1,
+		Burst:    2,
+		Key:      KeyByData("user"),
+		Interval: 10 * time.Second,
+		Now:      func() time.Time { return now },
+	})
+
+	for i := 0; i != 5; i++ {
+		for _, user := range []string{"A", "B"} {
+			e := Eprint(INFO, "hello")
+			e.Data["user"] = user
+			log.Log(e)
+		}
+	}
+
+	if len(events) != 4 {
+		t.Fatal("invalid number of events logged:", len(events))
+	}
+
+	// One token was refilled for each key, the next event triggers the
+	// summary since the interval has elapsed.
+	now = now.Add(10 * time.Second)
+	log.Log(Eprint(INFO, "world"))
+
+	if len(events) != 6 {
+		t.Fatal("invalid number of events logged:", len(events))
+	}
+
+	summary := events[4]
+
+	if summary.Level != WARN || summary.Message != "rate limit exceeded, 6 events were dropped" {
+		t.Error("invalid summary event:", summary)
+	}
+
+	if s := summary.Data.String(); s != `{"dropped":{"A":3,"B":3}}` {
+		t.Error("invalid summary data:", s)
+	}
+
+	if events[5].Message != "world" {
+		t.Error("invalid event logged after the summary:", events[5])
+	}
+}
+
+func TestRateLimiterFlush(t *testing.T) {
+	var events []Event
+
+	log := NewRateLimiter(LoggerFunc(func(e Event) error {
+		events = append(events, e)
+		return nil
+	}), RateLimiterConfig{
+		Rate:  1,
+		Burst: 1,
+		Key:   KeyByLevel,
+		Now:   func() time.Time { return time.Time{} },
+	})
+
+	log.Log(Eprint(INFO, "A"))
+	log.Log(Eprint(INFO, "B"))
+	log.Flush(context.Background())
+
+	if len(events) != 2 {
+		t.Fatal("invalid number of events logged:", len(events))
+	}
+
+	if s := events[1].Data.String(); s != `{"dropped":{"INFO":1}}` {
+		t.Error("invalid summary data:", s)
+	}
+
+	log.Flush(context.Background())
+
+	if len(events) != 2 {
+		t.Error("flushing without dropped events should not produce a summary")
+	}
+}
+
+func TestRateLimiterTimer(t *testing.T) {
+	events := make(chan Event, 10)
+
+	log := NewRateLimiter(LoggerFunc(func(e Event) error {
+		events <- e
+		return nil
+	}), RateLimiterConfig{
+		Rate:     1,
+		Burst:    1,
+		Key:      KeyByLevel,
+		Interval: 10 * time.Millisecond,
+	})
+
+	log.Log(Eprint(INFO, "A"))
+	log.Log(Eprint(INFO, "B"))
+
+	if e := <-events; e.Message != "A" {
+		t.Error("invalid event logged:", e)
+	}
+
+	select {
+	case e := <-events:
+		if s := e.Data.String(); s != `{"dropped":{"INFO":1}}` {
+			t.Error("invalid summary data:", s)
+		}
+	case <-time.After(time.Second):
+		t.Error("no summary emitted after the interval has elapsed")
+	}
+}
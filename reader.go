@@ -0,0 +1,123 @@
+package ecslogs
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/segmentio/encoding/json"
+)
+
+var ErrLineTooLong = errors.New("ecslogs: line too long")
+
+const DefaultMaxLineSize = 1024 * 1024
+
+// ReadError is returned by Reader.ReadEvent when a line couldn't be decoded,
+// the reader remains usable and the next call moves on to the following line.
+type ReadError struct {
+	Line int
+	Err  error
+}
+
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("ecslogs: line %d: %s", e.Line, e.Err)
+}
+
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
+type Reader struct {
+	r    *bufio.Reader
+	b    []byte
+	line int
+	max  int
+}
+
+func NewReader(r io.Reader) *Reader {
+	return NewReaderSize(r, DefaultMaxLineSize)
+}
+
+func NewReaderSize(r io.Reader, maxLineSize int) *Reader {
+	if maxLineSize <= 0 {
+		maxLineSize = DefaultMaxLineSize
+	}
+	return &Reader{
+		r:   bufio.NewReader(r),
+		max: maxLineSize,
+	}
+}
+
+func (r *Reader) Line() int {
+	return r.line
+}
+
+func (r *Reader) ReadEvent() (event Event, err error) {
+	var line []byte
+
+	for len(line) == 0 {
+		if line, err = r.readLine(); err != nil {
+			return
+		}
+		line = bytes.TrimSpace(line)
+	}
+
+	if err = decodeEvent(line, &event); err != nil {
+		err = &ReadError{Line: r.line, Err: err}
+	}
+
+	return
+}
+
+func (r *Reader) readLine() (line []byte, err error) {
+	var chunk []byte
+	var tooLong bool
+
+	r.b = r.b[:0]
+
+	for {
+		chunk, err = r.r.ReadSlice('\n')
+
+		if !tooLong {
+			if len(r.b)+len(chunk) > r.max {
+				tooLong, r.b = true, r.b[:0]
+			} else {
+				r.b = append(r.b, chunk...)
+			}
+		}
+
+		if err != bufio.ErrBufferFull {
+			break
+		}
+	}
+
+	if err == io.EOF && (len(r.b) != 0 || tooLong) {
+		err = nil
+	}
+
+	if err != nil {
+		return
+	}
+
+	r.line++
+
+	if tooLong {
+		err = &ReadError{Line: r.line, Err: ErrLineTooLong}
+		return
+	}
+
+	line = r.b
+	return
+}
+
+func decodeEvent(b []byte, event *Event) error {
+	r, err := json.Parse(b, event, json.UseNumber)
+
+	if err == nil && len(r) != 0 {
+		err = fmt.Errorf("unexpected trailing data after event: %q", r)
+	}
+
+	return err
+}
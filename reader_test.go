@@ -0,0 +1,101 @@
+package ecslogs
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReader(t *testing.T) {
+	now := time.Date(2016, 12, 5, 10, 31, 42, 0, time.UTC)
+	events := []Event{
+		Eprintf(INFO, "answer = %d", 42),
+		Eprintf(WARN, "an error was raised (%s)", syscall.Errno(2)),
+		{Level: ERROR, Time: now, Info: EventInfo{Host: "localhost"}, Data: EventData{"a": "b"}, Message: "hello"},
+	}
+
+	buf := &bytes.Buffer{}
+	log := NewLogger(buf)
+
+	for _, e := range events {
+		log.Log(e)
+	}
+
+	r := NewReader(buf)
+
+	for i, e := range events {
+		// The original errors cannot be decoded back.
+		for j := range e.Info.Errors {
+			e.Info.Errors[j].OriginalError = nil
+		}
+
+		if x, err := r.ReadEvent(); err != nil {
+			t.Errorf("event #%d: %s", i, err)
+		} else if s1, s2 := e.String(), x.String(); s1 != s2 {
+			t.Errorf("event #%d:\n- expected: %s\n- found:    %s", i, s1, s2)
+		}
+	}
+
+	if _, err := r.ReadEvent(); err != io.EOF {
+		t.Error("expected io.EOF after reading all events but got", err)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	lines := strings.Join([]string{
+		`{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{},"data":{},"message":"1"}`,
+		`{"level":"INFO",`,
+		``,
+		`{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{},"data":{"long":"` + strings.Repeat("-", 5000) + `"},"message":"2"}`,
+		`{"level":"LOUD","time":"0001-01-01T00:00:00Z","info":{},"data":{},"message":"3"}`,
+		`{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{},"data":{},"message":"4"}`,
+	}, "\n")
+
+	r := NewReaderSize(strings.NewReader(lines), 4096)
+	msgs := []string{}
+	errs := []int{}
+
+	for {
+		e, err := r.ReadEvent()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			if e, ok := err.(*ReadError); !ok {
+				t.Fatal(err)
+			} else {
+				errs = append(errs, e.Line)
+			}
+			continue
+		}
+
+		msgs = append(msgs, e.Message)
+	}
+
+	if s := strings.Join(msgs, ","); s != "1,4" {
+		t.Error("invalid messages read:", s)
+	}
+
+	if len(errs) != 3 || errs[0] != 2 || errs[1] != 4 || errs[2] != 5 {
+		t.Error("invalid errors reported:", errs)
+	}
+}
+
+func TestReaderLineTooLong(t *testing.T) {
+	r := NewReaderSize(strings.NewReader(strings.Repeat("-", 100)), 10)
+
+	if _, err := r.ReadEvent(); err == nil {
+		t.Error("expected an error but got none")
+	} else if e, ok := err.(*ReadError); !ok || e.Err != ErrLineTooLong {
+		t.Error("invalid error returned:", err)
+	}
+
+	if _, err := r.ReadEvent(); err != io.EOF {
+		t.Error("expected io.EOF but got", err)
+	}
+}
@@ -0,0 +1,105 @@
+package ecslogs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+type RecoverConfig struct {
+	// When Exit is true the program exits with ExitCode after the panic was
+	// logged, otherwise the recovered value is panicked again.
+	Exit     bool
+	ExitCode int
+	ExitFunc func(int)
+
+	// FlushTimeout bounds the time spent flushing the logger after the panic
+	// was logged.
+	FlushTimeout time.Duration
+}
+
+// Recover logs the value of a panic as an EMERG event carrying the stack of
+// the goroutine that panicked, it must be called with defer:
+//
+//	defer ecslogs.Recover(logger, ecslogs.RecoverConfig{})
+func Recover(logger Logger, c RecoverConfig) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	if c.ExitCode == 0 {
+		c.ExitCode = 2 // same as an uncaught panic
+	}
+
+	if c.ExitFunc == nil {
+		c.ExitFunc = os.Exit
+	}
+
+	if c.FlushTimeout <= 0 {
+		c.FlushTimeout = 5 * time.Second
+	}
+
+	logger.Log(makePanicEvent(v, captureStack(1, isPanicFrame)))
+
+	if f, ok := logger.(Flusher); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), c.FlushTimeout)
+		f.Flush(ctx)
+		cancel()
+	}
+
+	if c.Exit {
+		c.ExitFunc(c.ExitCode)
+		return
+	}
+
+	panic(v)
+}
+
+// Go runs fn in a new goroutine, panics are logged with logger before
+// crashing the program.
+func Go(logger Logger, fn func()) {
+	go func() {
+		defer Recover(logger, RecoverConfig{})
+		fn()
+	}()
+}
+
+func makePanicEvent(v interface{}, stack []FuncInfo) Event {
+	var e EventError
+
+	if err, ok := v.(error); ok {
+		e = MakeEventError(err)
+	} else {
+		e = EventError{
+			Type:  fmt.Sprintf("%T", v),
+			Error: fmt.Sprint(v),
+		}
+	}
+
+	if e.Stack == nil {
+		e.Stack = stack
+	}
+
+	info := EventInfo{Errors: []EventError{e}}
+
+	if len(stack) != 0 {
+		info.Source = stack[0].String()
+	}
+
+	return Event{
+		Level:   EMERG,
+		Time:    time.Now(),
+		Info:    info,
+		Data:    EventData{},
+		Message: "panic: " + e.Error,
+	}
+}
+
+// isPanicFrame returns true for the frames of the runtime that sit between a
+// deferred call and the function that panicked.
+func isPanicFrame(info FuncInfo) bool {
+	return strings.HasPrefix(info.File, "runtime/") || isLoggingFrame(info)
+}
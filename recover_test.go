@@ -0,0 +1,150 @@
+package ecslogs
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+type flushRecorder struct {
+	events  []Event
+	flushed bool
+}
+
+func (r *flushRecorder) Log(e Event) error {
+	r.events = append(r.events, e)
+	return nil
+}
+
+func (r *flushRecorder) Flush(ctx context.Context) error {
+	r.flushed = true
+	return nil
+}
+
+func panicking(v interface{}) {
+	panic(v)
+}
+
+func TestRecoverPanic(t *testing.T) {
+	r := &flushRecorder{}
+
+	func() {
+		defer func() {
+			if v := recover(); v != "oops" {
+				t.Error("invalid value panicked again:", v)
+			}
+		}()
+		defer Recover(r, RecoverConfig{})
+		panicking("oops")
+	}()
+
+	if !r.flushed {
+		t.Error("the logger was not flushed")
+	}
+
+	if len(r.events) != 1 {
+		t.Fatal("invalid number of events:", len(r.events))
+	}
+
+	e := r.events[0]
+
+	if e.Level != EMERG || e.Message != "panic: oops" {
+		t.Errorf("invalid event: %s %s", e.Level, e.Message)
+	}
+
+	if !strings.HasSuffix(e.Info.Source, ":panicking") {
+		t.Error("invalid source:", e.Info.Source)
+	}
+
+	err := e.Info.Errors[0]
+
+	if err.Type != "string" || err.Error != "oops" {
+		t.Errorf("invalid error: %#v", err)
+	}
+
+	if stack, _ := err.Stack.([]FuncInfo); len(stack) < 2 || stack[0].Func != "panicking" {
+		t.Errorf("invalid stack: %#v", err.Stack)
+	}
+}
+
+func TestRecoverExit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	code := -1
+
+	func() {
+		defer Recover(NewLogger(buf), RecoverConfig{
+			Exit:     true,
+			ExitFunc: func(c int) { code = c },
+		})
+		panicking(io.EOF)
+	}()
+
+	if code != 2 {
+		t.Error("invalid exit code:", code)
+	}
+
+	e, err := NewReader(buf).ReadEvent()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e.Level != EMERG || e.Message != "panic: EOF" || e.Info.Errors[0].Type != "*errors.errorString" {
+		t.Errorf("invalid event: %#v", e)
+	}
+}
+
+func TestRecoverExitBuffered(t *testing.T) {
+	buf := &bytes.Buffer{}
+	out := bufio.NewWriter(buf)
+	code := -1
+
+	func() {
+		defer Recover(NewLogger(out), RecoverConfig{
+			Exit: true,
+			ExitFunc: func(c int) {
+				code = c
+				if buf.Len() == 0 {
+					t.Error("the output was not flushed before exiting")
+				}
+			},
+		})
+		panicking(io.EOF)
+	}()
+
+	if code != 2 {
+		t.Error("invalid exit code:", code)
+	}
+
+	if e, err := NewReader(buf).ReadEvent(); err != nil {
+		t.Error(err)
+	} else if e.Level != EMERG {
+		t.Errorf("invalid event: %#v", e)
+	}
+}
+
+func TestRecoverNoPanic(t *testing.T) {
+	r := &flushRecorder{}
+
+	func() {
+		defer Recover(r, RecoverConfig{})
+	}()
+
+	if len(r.events) != 0 || r.flushed {
+		t.Error("events were logged without a panic")
+	}
+}
+
+func TestGo(t *testing.T) {
+	r := &flushRecorder{}
+	done := make(chan struct{})
+
+	Go(r, func() { close(done) })
+	<-done
+
+	if len(r.events) != 0 {
+		t.Error("unexpected events:", r.events)
+	}
+}
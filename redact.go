@@ -0,0 +1,290 @@
+package ecslogs
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"strings"
+)
+
+var (
+	RedactEmail        = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	RedactAWSAccessKey = regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)
+	RedactJWT          = regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`)
+)
+
+var DefaultRedactKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"authorization",
+	"apikey",
+	"api_key",
+	"cookie",
+}
+
+var DefaultRedactValues = []*regexp.Regexp{
+	RedactEmail,
+	RedactAWSAccessKey,
+	RedactJWT,
+}
+
+type RedactConfig struct {
+	// Keys are matched case-insensitively against the names of data fields,
+	// the values of fields containing one of them are masked entirely.
+	// Defaults to DefaultRedactKeys.
+	Keys []string
+
+	// Values are patterns masked in strings, defaults to DefaultRedactValues.
+	Values []*regexp.Regexp
+
+	// When Entropy is greater than zero, words of at least EntropyMinLen
+	// characters (default 20) with a Shannon entropy of Entropy bits per
+	// character or more are considered secrets and masked.
+	Entropy       float64
+	EntropyMinLen int
+
+	Mask string
+}
+
+// Redactor is a logger masking secrets in the events it forwards.
+type Redactor struct {
+	logger Logger
+	r      *redactor
+}
+
+func NewRedactor(logger Logger, c RedactConfig) *Redactor {
+	return &Redactor{logger: logger, r: newRedactor(c)}
+}
+
+func (r *Redactor) Log(event Event) error {
+	return r.logger.Log(r.r.redactEvent(event))
+}
+
+func (r *Redactor) Flush(ctx context.Context) error {
+	return flushLogger(ctx, r.logger)
+}
+
+type redactor struct {
+	keys          []string
+	values        []*regexp.Regexp
+	entropy       float64
+	entropyMinLen int
+	mask          string
+}
+
+func newRedactor(c RedactConfig) *redactor {
+	if c.Keys == nil {
+		c.Keys = DefaultRedactKeys
+	}
+
+	if c.Values == nil {
+		c.Values = DefaultRedactValues
+	}
+
+	if c.EntropyMinLen <= 0 {
+		c.EntropyMinLen = 20
+	}
+
+	if len(c.Mask) == 0 {
+		c.Mask = "[REDACTED]"
+	}
+
+	keys := make([]string, len(c.Keys))
+
+	for i, k := range c.Keys {
+		keys[i] = strings.ToLower(k)
+	}
+
+	return &redactor{
+		keys:          keys,
+		values:        c.Values,
+		entropy:       c.Entropy,
+		entropyMinLen: c.EntropyMinLen,
+		mask:          c.Mask,
+	}
+}
+
+func (r *redactor) redactEvent(event Event) Event {
+	event.Message = r.redactString(event.Message)
+
+	if len(event.Info.Errors) != 0 {
+		errors := make([]EventError, len(event.Info.Errors))
+
+		for i, e := range event.Info.Errors {
+			if s := r.redactString(e.Error); s != e.Error {
+				// The original error would be serialized as well and may
+				// carry the same secret.
+				e.Error, e.OriginalError = s, nil
+			}
+
+			if len(e.Causes) != 0 {
+				causes := make([]ErrorCause, len(e.Causes))
+				for j, c := range e.Causes {
+					c.Error = r.redactString(c.Error)
+					causes[j] = c
+				}
+				e.Causes = causes
+			}
+
+			e.Path = r.redactString(e.Path)
+			errors[i] = e
+		}
+
+		event.Info.Errors = errors
+	}
+
+	if event.Data != nil {
+		event.Data = EventData(r.redactMap(event.Data))
+	}
+
+	return event
+}
+
+func (r *redactor) redactValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case string:
+		return r.redactString(x)
+
+	case EventData:
+		return EventData(r.redactMap(x))
+
+	case map[string]interface{}:
+		return r.redactMap(x)
+
+	case map[string]string:
+		m := make(map[string]string, len(x))
+		for k, v := range x {
+			if r.redactKey(k) {
+				m[k] = r.mask
+			} else {
+				m[k] = r.redactString(v)
+			}
+		}
+		return m
+
+	case []interface{}:
+		s := make([]interface{}, len(x))
+		for i, v := range x {
+			s[i] = r.redactValue(v)
+		}
+		return s
+
+	case []EventData:
+		s := make([]EventData, len(x))
+		for i, m := range x {
+			s[i] = EventData(r.redactMap(m))
+		}
+		return s
+
+	case []map[string]interface{}:
+		s := make([]map[string]interface{}, len(x))
+		for i, m := range x {
+			s[i] = r.redactMap(m)
+		}
+		return s
+
+	case []string:
+		s := make([]string, len(x))
+		for i, v := range x {
+			s[i] = r.redactString(v)
+		}
+		return s
+
+	default:
+		return v
+	}
+}
+
+func (r *redactor) redactMap(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+
+	for k, v := range m {
+		if r.redactKey(k) {
+			c[k] = r.mask
+		} else {
+			c[k] = r.redactValue(v)
+		}
+	}
+
+	return c
+}
+
+func (r *redactor) redactKey(k string) bool {
+	k = strings.ToLower(k)
+
+	for _, key := range r.keys {
+		if strings.Contains(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *redactor) redactString(s string) string {
+	for _, re := range r.values {
+		s = re.ReplaceAllLiteralString(s, r.mask)
+	}
+
+	if r.entropy > 0 && len(s) >= r.entropyMinLen {
+		s = r.redactEntropy(s)
+	}
+
+	return s
+}
+
+func (r *redactor) redactEntropy(s string) string {
+	var b strings.Builder
+	var i int
+
+	for i < len(s) {
+		j := i
+		for j < len(s) && !isWordSeparator(s[j]) {
+			j++
+		}
+
+		if word := s[i:j]; len(word) >= r.entropyMinLen && shannonEntropy(word) >= r.entropy {
+			b.WriteString(r.mask)
+		} else {
+			b.WriteString(word)
+		}
+
+		if j < len(s) {
+			b.WriteByte(s[j])
+			j++
+		}
+
+		i = j
+	}
+
+	return b.String()
+}
+
+func isWordSeparator(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '"', '\'', '`', ',', ';', ':', '=', '&', '?', '(', ')', '[', ']', '{', '}', '<', '>':
+		return true
+	}
+	return false
+}
+
+func shannonEntropy(s string) (h float64) {
+	var counts [256]int
+
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+
+	n := float64(len(s))
+
+	for _, c := range counts {
+		if c != 0 {
+			p := float64(c) / n
+			h -= p * math.Log2(p)
+		}
+	}
+
+	return
+}
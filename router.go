@@ -0,0 +1,119 @@
+package ecslogs
+
+import (
+	"context"
+	"reflect"
+	"strings"
+)
+
+type Predicate func(Event) bool
+
+type Route struct {
+	Match  Predicate
+	Logger Logger
+}
+
+// Router fans out events to every route that matches them, a route with no
+// predicate receives all events.
+type Router struct {
+	routes []Route
+}
+
+func NewRouter(routes ...Route) *Router {
+	return &Router{routes: append([]Route{}, routes...)}
+}
+
+func (r *Router) Log(event Event) error {
+	var errs MultiError
+
+	for _, route := range r.routes {
+		if route.Match == nil || route.Match(event) {
+			if err := route.Logger.Log(event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+func (r *Router) Flush(ctx context.Context) error {
+	var errs MultiError
+
+	for _, route := range r.routes {
+		if f, ok := route.Logger.(Flusher); ok {
+			if err := f.Flush(ctx); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs.err()
+}
+
+func MatchLevel(lvl Level) Predicate {
+	return func(e Event) bool { return e.Level != NONE && e.Level <= lvl }
+}
+
+func MatchDataKey(key string) Predicate {
+	return func(e Event) bool { _, ok := e.Data.Get(key); return ok }
+}
+
+func MatchDataValue(key string, value interface{}) Predicate {
+	return func(e Event) bool { v, ok := e.Data.Get(key); return ok && reflect.DeepEqual(v, value) }
+}
+
+func MatchSource(prefix string) Predicate {
+	return func(e Event) bool { return strings.HasPrefix(e.Info.Source, prefix) }
+}
+
+func MatchAll(predicates ...Predicate) Predicate {
+	return func(e Event) bool {
+		for _, p := range predicates {
+			if !p(e) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func MatchAny(predicates ...Predicate) Predicate {
+	return func(e Event) bool {
+		for _, p := range predicates {
+			if p(e) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func MatchNot(predicate Predicate) Predicate {
+	return func(e Event) bool { return !predicate(e) }
+}
+
+// MultiError aggregates the errors returned by loggers that are used
+// together.
+type MultiError []error
+
+func (m MultiError) Error() string {
+	s := make([]string, len(m))
+
+	for i, err := range m {
+		s[i] = err.Error()
+	}
+
+	return strings.Join(s, "; ")
+}
+
+func (m MultiError) Unwrap() []error {
+	return m
+}
+
+func (m MultiError) err() error {
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,80 @@
+package ecslogs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRouter(t *testing.T) {
+	var all, errs, audit []string
+
+	record := func(msgs *[]string) Logger {
+		return LoggerFunc(func(e Event) error {
+			*msgs = append(*msgs, e.Message)
+			return nil
+		})
+	}
+
+	router := NewRouter(
+		Route{Logger: record(&all)},
+		Route{Match: MatchLevel(ERROR), Logger: record(&errs)},
+		Route{Match: MatchAll(MatchDataValue("audit", true), MatchSource("api/")), Logger: record(&audit)},
+	)
+
+	events := []Event{
+		Eprint(INFO, "A"),
+		Eprint(ERROR, "B"),
+		Eprint(CRIT, "C"),
+		{Level: INFO, Message: "D", Data: EventData{"audit": true}, Info: EventInfo{Source: "api/handler.go:42:F"}},
+		{Level: INFO, Message: "E", Data: EventData{"audit": true}, Info: EventInfo{Source: "main.go:1:main"}},
+		{Level: NONE, Message: "F"},
+	}
+
+	for _, e := range events {
+		if err := router.Log(e); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if s := fmt.Sprint(all); s != "[A B C D E F]" {
+		t.Error("invalid events sent to the default route:", s)
+	}
+
+	if s := fmt.Sprint(errs); s != "[B C]" {
+		t.Error("invalid events sent to the error route:", s)
+	}
+
+	if s := fmt.Sprint(audit); s != "[D]" {
+		t.Error("invalid events sent to the audit route:", s)
+	}
+}
+
+func TestRouterErrors(t *testing.T) {
+	var count int
+
+	errA := errors.New("A")
+	errB := errors.New("B")
+
+	fail := func(err error) Logger {
+		return LoggerFunc(func(e Event) error { return err })
+	}
+
+	router := NewRouter(
+		Route{Logger: fail(errA)},
+		Route{Logger: LoggerFunc(func(e Event) error { count++; return nil })},
+		Route{Logger: fail(errB)},
+	)
+
+	err := router.Log(Eprint(INFO, ""))
+
+	if count != 1 {
+		t.Error("the event was not sent to all routes")
+	}
+
+	if m, ok := err.(MultiError); !ok || len(m) != 2 || m[0] != errA || m[1] != errB {
+		t.Errorf("invalid error returned: %#v", err)
+	} else if s := m.Error(); s != "A; B" {
+		t.Error("invalid error message:", s)
+	}
+}
@@ -8,9 +8,9 @@ import (
 )
 
 type FuncInfo struct {
-	File string
-	Func string
-	Line int
+	File string `json:"file"`
+	Func string `json:"func"`
+	Line int    `json:"line"`
 }
 
 func (info FuncInfo) String() string {
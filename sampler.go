@@ -0,0 +1,116 @@
+package ecslogs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SamplingPolicy lets the First events with the same level and message go
+// through on every tick, then only one every Thereafter. The zero value
+// disables sampling.
+type SamplingPolicy struct {
+	First      int
+	Thereafter int
+}
+
+type SamplerConfig struct {
+	Tick time.Duration
+
+	// Policy applies to levels less severe than ERROR, it defaults to letting
+	// the first 100 events through then one every 100.
+	Policy SamplingPolicy
+
+	// Levels overrides the policy of specific levels.
+	Levels map[Level]SamplingPolicy
+
+	Now func() time.Time
+}
+
+// Sampler is a logger forwarding a sample of the events it receives.
+type Sampler struct {
+	logger   Logger
+	tick     time.Duration
+	now      func() time.Time
+	policies [TRACE + 1]SamplingPolicy
+	mutex    sync.Mutex
+	counts   map[samplerKey]int
+	last     time.Time
+}
+
+type samplerKey struct {
+	lvl Level
+	msg string
+}
+
+func NewSampler(logger Logger, c SamplerConfig) *Sampler {
+	if c.Tick <= 0 {
+		c.Tick = time.Second
+	}
+
+	if c.Policy == (SamplingPolicy{}) {
+		c.Policy = SamplingPolicy{First: 100, Thereafter: 100}
+	}
+
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+
+	s := &Sampler{
+		logger: logger,
+		tick:   c.Tick,
+		now:    c.Now,
+		counts: map[samplerKey]int{},
+	}
+
+	for lvl := ERROR + 1; lvl <= TRACE; lvl++ {
+		s.policies[lvl] = c.Policy
+	}
+
+	for lvl, policy := range c.Levels {
+		if lvl >= NONE && lvl <= TRACE {
+			s.policies[lvl] = policy
+		}
+	}
+
+	return s
+}
+
+func (s *Sampler) Log(event Event) error {
+	var policy SamplingPolicy
+
+	if event.Level >= NONE && event.Level <= TRACE {
+		policy = s.policies[event.Level]
+	}
+
+	if policy == (SamplingPolicy{}) {
+		return s.logger.Log(event)
+	}
+
+	s.mutex.Lock()
+
+	if now := s.now().Truncate(s.tick); !now.Equal(s.last) {
+		s.last, s.counts = now, map[samplerKey]int{}
+	}
+
+	k := samplerKey{event.Level, event.Message}
+	n := s.counts[k] + 1
+	s.counts[k] = n
+
+	s.mutex.Unlock()
+
+	if n <= policy.First {
+		return s.logger.Log(event)
+	}
+
+	if policy.Thereafter <= 0 || (n-policy.First)%policy.Thereafter != 0 {
+		return nil
+	}
+
+	event.Data = copyEventData(event.Data, EventData{"sampleRate": policy.Thereafter})
+	return s.logger.Log(event)
+}
+
+func (s *Sampler) Flush(ctx context.Context) error {
+	return flushLogger(ctx, s.logger)
+}
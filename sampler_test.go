@@ -0,0 +1,66 @@
+package ecslogs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	var events []Event
+
+	now := time.Date(2016, 12, 5, 10, 31, 42, 0, time.UTC)
+	log := NewSampler(LoggerFunc(func(e Event) error {
+		events = append(events, e)
+		return nil
+	}), SamplerConfig{
+		Tick:   time.Second,
+		Policy: SamplingPolicy{First: 2, Thereafter: 3},
+		Levels: map[Level]SamplingPolicy{DEBUG: {First: 1}},
+		Now:    func() time.Time { return now },
+	})
+
+	for i := 0; i != 10; i++ {
+		log.Log(Eprint(WARN, "A"))
+		log.Log(Eprint(WARN, "B"))
+		log.Log(Eprint(ERROR, "C"))
+		log.Log(Eprint(DEBUG, "D"))
+	}
+
+	now = now.Add(time.Second)
+	log.Log(Eprint(WARN, "A"))
+
+	count := map[string]int{}
+	sampled := 0
+
+	for _, e := range events {
+		count[e.Message]++
+
+		if rate, ok := e.Data["sampleRate"]; ok {
+			if rate != 3 {
+				t.Error("invalid sample rate:", rate)
+			}
+			sampled++
+		}
+	}
+
+	// 2 first events + the 5th and 8th, then 1 after the next tick
+	if count["A"] != 5 {
+		t.Error("invalid number of A events:", count["A"])
+	}
+
+	if count["B"] != 4 {
+		t.Error("invalid number of B events:", count["B"])
+	}
+
+	if count["C"] != 10 {
+		t.Error("ERROR events should not be sampled:", count["C"])
+	}
+
+	if count["D"] != 1 {
+		t.Error("invalid number of D events:", count["D"])
+	}
+
+	if sampled != 4 {
+		t.Error("invalid number of events annotated with the sample rate:", sampled)
+	}
+}
@@ -0,0 +1,116 @@
+package ecslogs
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// StackTrace returns the frames of the stack trace carried by err or one of
+// the errors it wraps, the innermost stack is used since it is usually the
+// closest to where the error was created. Any error with a StackTrace method
+// returning a slice of program counters is supported, which includes the
+// errors of github.com/pkg/errors.
+func StackTrace(err error) (stack []FuncInfo) {
+	walkErrors(err, func(err error, depth int) {
+		if s := errorStackTrace(err); len(s) != 0 {
+			stack = s
+		}
+	})
+	return
+}
+
+// CaptureStack returns the stack of the calling goroutine, skip is the number
+// of frames to skip, 0 being the caller of CaptureStack.
+func CaptureStack(skip int) []FuncInfo {
+	return captureStack(skip+1, nil)
+}
+
+func captureStack(skip int, ignore func(FuncInfo) bool) []FuncInfo {
+	pcs := make([]uintptr, 64)
+	pcs = pcs[:runtime.Callers(skip+2, pcs)]
+
+	stack := make([]FuncInfo, 0, len(pcs))
+
+	for _, pc := range pcs {
+		if info, ok := GetFuncInfo(pc - 1); ok {
+			if len(stack) == 0 && ignore != nil && ignore(info) {
+				continue
+			}
+			stack = append(stack, info)
+		}
+	}
+
+	return stack
+}
+
+// withStack sets the stack of the calling goroutine on errors that don't carry
+// one, an error with only the stack is added when there are none.
+func withStack(errors []EventError) []EventError {
+	var stack []FuncInfo
+
+	if len(errors) == 0 {
+		return []EventError{{Stack: captureStack(1, isLoggingFrame)}}
+	}
+
+	for i, e := range errors {
+		if e.Stack == nil {
+			if stack == nil {
+				stack = captureStack(1, isLoggingFrame)
+				errors = append([]EventError{}, errors...)
+			}
+			errors[i].Stack = stack
+		}
+	}
+
+	return errors
+}
+
+func errorStackTrace(err error) []FuncInfo {
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+
+	if t := m.Type().Out(0); t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	frames := m.Call(nil)[0]
+	stack := make([]FuncInfo, 0, frames.Len())
+
+	for i, n := 0, frames.Len(); i != n; i++ {
+		// Program counters in stack traces are return addresses, the call
+		// instruction is right before them.
+		if info, ok := GetFuncInfo(uintptr(frames.Index(i).Uint()) - 1); ok {
+			stack = append(stack, info)
+		}
+	}
+
+	return stack
+}
+
+var stackIgnorePackages = []string{
+	"github.com/segmentio/ecs-logs",
+	"github.com/sirupsen/logrus",
+	"github.com/apex/log",
+	"github.com/go-playground/log",
+}
+
+// isLoggingFrame returns true for frames of this package and the logging
+// libraries it integrates with, they are removed from the top of captured
+// stacks.
+func isLoggingFrame(info FuncInfo) bool {
+	if strings.HasSuffix(info.File, "_test.go") {
+		return false
+	}
+
+	for _, pkg := range stackIgnorePackages {
+		if strings.Contains(info.File, pkg) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+package ecslogs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type frame uintptr
+
+type stackError struct {
+	error
+	stack []frame
+}
+
+func (e *stackError) StackTrace() []frame { return e.stack }
+
+func newStackError(err error) error {
+	pcs := make([]uintptr, 32)
+	pcs = pcs[:runtime.Callers(2, pcs)]
+
+	stack := make([]frame, len(pcs))
+	for i, pc := range pcs {
+		stack[i] = frame(pc)
+	}
+
+	return &stackError{err, stack}
+}
+
+func TestStackTrace(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", newStackError(io.EOF))
+	stack := StackTrace(err)
+
+	if len(stack) == 0 {
+		t.Fatal("no stack trace found")
+	}
+
+	if f := stack[0]; f.Func != "TestStackTrace" || !strings.HasSuffix(f.File, "stack_test.go") {
+		t.Errorf("invalid first frame: %#v", f)
+	}
+
+	if e := MakeEventError(err); e.Stack == nil {
+		t.Error("no stack trace set on the event error")
+	}
+
+	if e := MakeEventError(io.EOF); e.Stack != nil {
+		t.Errorf("unexpected stack trace set on the event error: %#v", e.Stack)
+	}
+}
+
+func TestCaptureStack(t *testing.T) {
+	stack := CaptureStack(0)
+
+	if len(stack) == 0 {
+		t.Fatal("no stack captured")
+	}
+
+	if f := stack[0]; f.Func != "TestCaptureStack" {
+		t.Errorf("invalid first frame: %#v", f)
+	}
+}
+
+func TestLoggerStackLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := NewLoggerWith(Config{Output: buf, StackLevel: ERROR})
+
+	log.Log(Eprint(WARN, io.EOF))
+	log.Log(Eprint(ERROR, io.EOF))
+
+	r := NewReader(buf)
+
+	if e, err := r.ReadEvent(); err != nil {
+		t.Error(err)
+	} else if s := e.Info.Errors[0].Stack; s != nil {
+		t.Errorf("unexpected stack on a WARN event: %#v", s)
+	}
+
+	if e, err := r.ReadEvent(); err != nil {
+		t.Error(err)
+	} else if s, _ := e.Info.Errors[0].Stack.([]interface{}); len(s) == 0 {
+		t.Error("no stack set on an ERROR event")
+	} else if f := s[0].(map[string]interface{}); f["func"] != "TestLoggerStackLevel" {
+		t.Errorf("invalid first frame: %#v", f)
+	}
+}
+
+func TestLoggerStackLevelWithoutErrors(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := NewLoggerWith(Config{Output: buf, StackLevel: CRIT})
+
+	log.Log(Eprint(CRIT, "hello"))
+
+	e, err := NewReader(buf).ReadEvent()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(e.Info.Errors) != 1 {
+		t.Fatal("no error carrying the stack was added:", e.Info.Errors)
+	}
+
+	if s, _ := e.Info.Errors[0].Stack.([]interface{}); len(s) == 0 {
+		t.Error("no stack set on a CRIT event")
+	} else if f := s[0].(map[string]interface{}); f["func"] != "TestLoggerStackLevelWithoutErrors" {
+		t.Errorf("invalid first frame: %#v", f)
+	}
+}
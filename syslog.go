@@ -0,0 +1,387 @@
+package ecslogs
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SyslogFormat int
+
+const (
+	RFC5424 SyslogFormat = iota
+	RFC3164
+)
+
+// The syslog facilities, LOG_USER is the default.
+const (
+	LOG_KERN = iota
+	LOG_USER
+	LOG_MAIL
+	LOG_DAEMON
+	LOG_AUTH
+	LOG_SYSLOG
+	LOG_LPR
+	LOG_NEWS
+	LOG_UUCP
+	LOG_CRON
+	LOG_AUTHPRIV
+	LOG_FTP
+	_
+	_
+	_
+	_
+	LOG_LOCAL0
+	LOG_LOCAL1
+	LOG_LOCAL2
+	LOG_LOCAL3
+	LOG_LOCAL4
+	LOG_LOCAL5
+	LOG_LOCAL6
+	LOG_LOCAL7
+)
+
+// DefaultSyslogDataID is the SD-ID of the structured data element carrying
+// event data, it uses the enterprise number reserved for documentation and
+// should be replaced by one registered to the organization.
+const DefaultSyslogDataID = "data@32473"
+
+// DefaultSyslogInfoID is the SD-ID of the structured data element carrying the
+// source and error of events.
+const DefaultSyslogInfoID = "info@32473"
+
+type SyslogConfig struct {
+	// Network is one of "udp", "tcp" or "unixgram" (or their variants like
+	// "udp4"), messages sent over stream connections use octet-counted
+	// framing. When both are empty the local syslog daemon is reached at
+	// /dev/log.
+	Network string
+	Address string
+
+	Format   SyslogFormat
+	Facility int
+
+	// Hostname, AppName and ProcID default to the host name, program name and
+	// process ID.
+	Hostname string
+	AppName  string
+	ProcID   string
+
+	// DataID and InfoID are the SD-IDs of the structured data elements
+	// carrying the event data and info in RFC 5424 messages, the info element
+	// is also appended to RFC 3164 messages.
+	DataID string
+	InfoID string
+
+	Timeout time.Duration
+}
+
+// SyslogLogger is a logger sending events to a syslog server.
+type SyslogLogger struct {
+	config SyslogConfig
+	mutex  sync.Mutex
+	conn   net.Conn
+	stream bool
+	buf    []byte
+}
+
+func NewSyslogLogger(c SyslogConfig) (*SyslogLogger, error) {
+	if len(c.Network) == 0 && len(c.Address) == 0 {
+		c.Network, c.Address = "unixgram", "/dev/log"
+	}
+
+	if c.Facility == 0 {
+		c.Facility = LOG_USER
+	}
+
+	if len(c.Hostname) == 0 {
+		c.Hostname, _ = os.Hostname()
+	}
+
+	if len(c.AppName) == 0 {
+		c.AppName = filepath.Base(os.Args[0])
+	}
+
+	if len(c.ProcID) == 0 {
+		c.ProcID = strconv.Itoa(os.Getpid())
+	}
+
+	if len(c.DataID) == 0 {
+		c.DataID = DefaultSyslogDataID
+	}
+
+	if len(c.InfoID) == 0 {
+		c.InfoID = DefaultSyslogInfoID
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = 5 * time.Second
+	}
+
+	s := &SyslogLogger{config: c}
+
+	switch c.Network {
+	case "udp", "udp4", "udp6", "unixgram":
+	case "tcp", "tcp4", "tcp6", "unix":
+		s.stream = true
+	default:
+		return nil, fmt.Errorf("ecslogs: unsupported syslog network: %q", c.Network)
+	}
+
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *SyslogLogger) Log(event Event) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.conn == nil {
+		// Connections are re-established when sending a previous message
+		// failed, Close also sets conn to nil.
+		if err = s.connect(); err != nil {
+			return
+		}
+	}
+
+	s.buf = s.buf[:0]
+
+	switch s.config.Format {
+	case RFC3164:
+		s.buf = appendSyslog3164(s.buf, event, s.config)
+	default:
+		s.buf = appendSyslog5424(s.buf, event, s.config)
+	}
+
+	if s.stream {
+		b := make([]byte, 0, len(s.buf)+12)
+		b = strconv.AppendInt(b, int64(len(s.buf)), 10)
+		b = append(b, ' ')
+		s.buf = append(b, s.buf...)
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(s.config.Timeout))
+
+	if _, err = s.conn.Write(s.buf); err != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+
+	return
+}
+
+func (s *SyslogLogger) Close() (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.conn != nil {
+		err = s.conn.Close()
+		s.conn = nil
+	}
+
+	return
+}
+
+func (s *SyslogLogger) connect() (err error) {
+	s.conn, err = net.DialTimeout(s.config.Network, s.config.Address, s.config.Timeout)
+	return
+}
+
+func syslogPriority(event Event, facility int) int {
+	return facility*8 + event.Level.Severity()
+}
+
+func appendSyslog5424(b []byte, event Event, c SyslogConfig) []byte {
+	b = append(b, '<')
+	b = strconv.AppendInt(b, int64(syslogPriority(event, c.Facility)), 10)
+	b = append(b, ">1 "...)
+
+	if event.Time.IsZero() {
+		b = append(b, '-')
+	} else {
+		b = event.Time.AppendFormat(b, "2006-01-02T15:04:05.000000Z07:00")
+	}
+
+	b = append(b, ' ')
+	b = appendSyslogHeader(b, c.Hostname, 255)
+	b = append(b, ' ')
+	b = appendSyslogHeader(b, c.AppName, 48)
+	b = append(b, ' ')
+	b = appendSyslogHeader(b, c.ProcID, 128)
+	b = append(b, " - "...)
+
+	data := make(map[string]string, len(event.Data))
+	flattenSyslogData(data, "", event.Data)
+	info := syslogInfoParams(event.Info)
+
+	if len(data) == 0 && len(info) == 0 {
+		b = append(b, '-')
+	} else {
+		if len(data) != 0 {
+			b = appendSyslogElement(b, c.DataID, data)
+		}
+		if len(info) != 0 {
+			b = appendSyslogElement(b, c.InfoID, info)
+		}
+	}
+
+	if len(event.Message) != 0 {
+		b = append(b, ' ')
+		b = append(b, event.Message...)
+	}
+
+	return b
+}
+
+func appendSyslog3164(b []byte, event Event, c SyslogConfig) []byte {
+	t := event.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	b = append(b, '<')
+	b = strconv.AppendInt(b, int64(syslogPriority(event, c.Facility)), 10)
+	b = append(b, '>')
+	b = t.AppendFormat(b, time.Stamp)
+	b = append(b, ' ')
+	b = appendSyslogHeader(b, c.Hostname, 255)
+	b = append(b, ' ')
+	b = appendSyslogHeader(b, c.AppName, 32)
+	b = append(b, '[')
+	b = append(b, c.ProcID...)
+	b = append(b, "]: "...)
+	b = append(b, event.Message...)
+
+	if len(event.Data) != 0 {
+		if data, err := appendMap(nil, event.Data, 0); err == nil {
+			b = append(b, ' ')
+			b = append(b, data...)
+		}
+	}
+
+	if info := syslogInfoParams(event.Info); len(info) != 0 {
+		b = append(b, ' ')
+		b = appendSyslogElement(b, c.InfoID, info)
+	}
+
+	return b
+}
+
+// appendSyslogHeader appends a header field of a RFC 5424 message, they only
+// allow printable ASCII characters and use "-" for empty values.
+func appendSyslogHeader(b []byte, s string, maxLen int) []byte {
+	if len(s) == 0 {
+		return append(b, '-')
+	}
+
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+
+	for i := 0; i != len(s); i++ {
+		if c := s[i]; c > ' ' && c < 0x7f {
+			b = append(b, c)
+		} else {
+			b = append(b, '_')
+		}
+	}
+
+	return b
+}
+
+// appendSyslogElement appends a structured data element of a RFC 5424
+// message with the given parameters.
+func appendSyslogElement(b []byte, id string, params map[string]string) []byte {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b = append(b, '[')
+	b = append(b, id...)
+
+	for _, name := range names {
+		b = append(b, ' ')
+		b = appendSyslogHeader(b, strings.Map(syslogParamNameChar, name), 32)
+		b = append(b, '=', '"')
+
+		value := params[name]
+
+		for i := 0; i != len(value); i++ {
+			switch c := value[i]; c {
+			case '"', '\\', ']':
+				b = append(b, '\\', c)
+			default:
+				b = append(b, c)
+			}
+		}
+
+		b = append(b, '"')
+	}
+
+	return append(b, ']')
+}
+
+// syslogInfoParams returns the parameters of the structured data element
+// carrying the source and first error of an event.
+func syslogInfoParams(info EventInfo) map[string]string {
+	params := make(map[string]string, 4)
+
+	if len(info.Source) != 0 {
+		params["source"] = info.Source
+	}
+
+	if len(info.Errors) != 0 {
+		e := info.Errors[0]
+
+		if len(e.Type) != 0 {
+			params["errorType"] = e.Type
+		}
+
+		if len(e.Error) != 0 {
+			params["error"] = e.Error
+		}
+
+		if e.Errno != 0 {
+			params["errno"] = strconv.Itoa(e.Errno)
+		}
+	}
+
+	return params
+}
+
+// flattenSyslogData flattens nested data into dot-separated parameter names.
+func flattenSyslogData(params map[string]string, prefix string, data EventData) {
+	for k, v := range data {
+		switch x := v.(type) {
+		case EventData:
+			flattenSyslogData(params, prefix+k+".", x)
+		case map[string]interface{}:
+			flattenSyslogData(params, prefix+k+".", EventData(x))
+		case string:
+			params[prefix+k] = x
+		default:
+			if b, err := appendValue(nil, v, 0); err == nil {
+				params[prefix+k] = string(b)
+			}
+		}
+	}
+}
+
+func syslogParamNameChar(r rune) rune {
+	switch r {
+	case '=', ' ', ']', '"':
+		return '_'
+	}
+	return r
+}
@@ -0,0 +1,211 @@
+package ecslogs
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var syslogTestConfig = SyslogConfig{
+	Facility: LOG_LOCAL0,
+	Hostname: "host",
+	AppName:  "app",
+	ProcID:   "42",
+	DataID:   DefaultSyslogDataID,
+	InfoID:   DefaultSyslogInfoID,
+}
+
+func TestAppendSyslog5424(t *testing.T) {
+	tests := []struct {
+		event Event
+		msg   string
+	}{
+		{
+			event: Event{
+				Level:   ERROR,
+				Time:    time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC),
+				Message: "hello",
+				Data: EventData{
+					"user":  "me",
+					"count": 3,
+					"req":   EventData{"path": `/a"b]`},
+					"a b":   true,
+				},
+			},
+			msg: `<131>1 2020-01-02T03:04:05.000006Z host app 42 - [data@32473 a_b="true" count="3" req.path="/a\"b\]" user="me"] hello`,
+		},
+		{
+			event: Event{
+				Level:   ERROR,
+				Info:    EventInfo{Source: "main.go:42:main", Errors: []EventError{{Type: "*os.SyscallError", Error: "connection refused", Errno: 111}}},
+				Message: "hello",
+			},
+			msg: `<131>1 - host app 42 - [info@32473 errno="111" error="connection refused" errorType="*os.SyscallError" source="main.go:42:main"] hello`,
+		},
+		{
+			event: Event{
+				Level: INFO,
+				Info:  EventInfo{Source: "main.go:42:main"},
+				Data:  EventData{"user": "me"},
+			},
+			msg: `<134>1 - host app 42 - [data@32473 user="me"][info@32473 source="main.go:42:main"]`,
+		},
+		{
+			event: Event{Level: TRACE, Message: "hello"},
+			msg:   `<135>1 - host app 42 - - hello`,
+		},
+		{
+			event: Event{Level: NONE},
+			msg:   `<134>1 - host app 42 - -`,
+		},
+	}
+
+	for _, test := range tests {
+		if msg := string(appendSyslog5424(nil, test.event, syslogTestConfig)); msg != test.msg {
+			t.Errorf("\n- expected: %s\n- found:    %s", test.msg, msg)
+		}
+	}
+}
+
+func TestAppendSyslog3164(t *testing.T) {
+	e := Event{
+		Level:   WARN,
+		Time:    time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: "hello",
+		Info:    EventInfo{Errors: []EventError{{Error: "EOF"}}},
+		Data:    EventData{"user": "me"},
+	}
+
+	const expected = `<132>Jan  2 03:04:05 host app[42]: hello {"user":"me"} [info@32473 error="EOF"]`
+
+	if msg := string(appendSyslog3164(nil, e, syslogTestConfig)); msg != expected {
+		t.Errorf("\n- expected: %s\n- found:    %s", expected, msg)
+	}
+}
+
+func TestSyslogLoggerUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	testSyslogPacket(t, conn, "udp", conn.LocalAddr().String())
+}
+
+func TestSyslogLoggerUnixgram(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+
+	testSyslogPacket(t, conn, "unixgram", path)
+}
+
+func testSyslogPacket(t *testing.T, conn net.PacketConn, network string, address string) {
+	c := syslogTestConfig
+	c.Network, c.Address = network, address
+
+	log, err := NewSyslogLogger(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	for _, msg := range []string{"A", "B"} {
+		if err := log.Log(Eprint(INFO, msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+
+	for _, msg := range []string{"A", "B"} {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "<134>1 - host app 42 - - " + msg
+
+		if s := string(buf[:n]); s != expected {
+			t.Errorf("\n- expected: %s\n- found:    %s", expected, s)
+		}
+	}
+}
+
+func TestSyslogLoggerTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	msgs := make(chan string, 2)
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+
+		for {
+			size, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+
+			n, _ := strconv.Atoi(strings.TrimSpace(size))
+			b := make([]byte, n)
+
+			if _, err := io.ReadFull(r, b); err != nil {
+				return
+			}
+
+			msgs <- string(b)
+		}
+	}()
+
+	c := syslogTestConfig
+	c.Network, c.Address, c.Format = "tcp", l.Addr().String(), RFC3164
+
+	log, err := NewSyslogLogger(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	log.Log(Event{Level: INFO, Message: "A", Time: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)})
+	log.Log(Event{Level: INFO, Message: "B\nC", Time: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)})
+
+	for _, expected := range []string{
+		"<134>Jan  2 03:04:05 host app[42]: A",
+		"<134>Jan  2 03:04:05 host app[42]: B\nC",
+	} {
+		select {
+		case s := <-msgs:
+			if s != expected {
+				t.Errorf("\n- expected: %q\n- found:    %q", expected, s)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for syslog message")
+		}
+	}
+}
+
+func TestSyslogLoggerNetwork(t *testing.T) {
+	if _, err := NewSyslogLogger(SyslogConfig{Network: "ip", Address: "localhost"}); err == nil {
+		t.Error("no error returned for an unsupported network")
+	}
+}
@@ -0,0 +1,189 @@
+package ecslogs
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// TraceIDKey and SpanIDKey are the keys under which trace identifiers
+	// are set in the data of events.
+	TraceIDKey = "traceId"
+	SpanIDKey  = "spanId"
+)
+
+var (
+	ErrInvalidTraceparent = errors.New("ecslogs: invalid traceparent header")
+	ErrInvalidXRayTraceID = errors.New("ecslogs: invalid X-Amzn-Trace-Id header")
+)
+
+// TraceContext identifies the trace and span that an event belongs to.
+type TraceContext struct {
+	TraceID string
+	SpanID  string
+	Sampled bool
+}
+
+// Data returns the identifiers of the trace as event data.
+func (t TraceContext) Data() EventData {
+	data := EventData{TraceIDKey: t.TraceID}
+
+	if len(t.SpanID) != 0 {
+		data[SpanIDKey] = t.SpanID
+	}
+
+	return data
+}
+
+// TraceExtractor is implemented by types that find the trace of a context,
+// for example the span context of a tracing library.
+type TraceExtractor interface {
+	ExtractTrace(context.Context) (TraceContext, bool)
+}
+
+type TraceExtractorFunc func(context.Context) (TraceContext, bool)
+
+func (f TraceExtractorFunc) ExtractTrace(ctx context.Context) (TraceContext, bool) {
+	return f(ctx)
+}
+
+var traceExtractors struct {
+	sync.RWMutex
+	list []TraceExtractor
+}
+
+// RegisterTraceExtractor adds x to the extractors used by TraceFromContext,
+// it is usually called once when the program starts.
+func RegisterTraceExtractor(x TraceExtractor) {
+	traceExtractors.Lock()
+	traceExtractors.list = append(traceExtractors.list, x)
+	traceExtractors.Unlock()
+}
+
+func ContextWithTrace(ctx context.Context, trace TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey, trace)
+}
+
+// TraceFromContext returns the trace set on ctx with ContextWithTrace, or if
+// there are none the first one found by the registered extractors.
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	if trace, ok := ctx.Value(traceContextKey).(TraceContext); ok {
+		return trace, true
+	}
+
+	traceExtractors.RLock()
+	defer traceExtractors.RUnlock()
+
+	for _, x := range traceExtractors.list {
+		if trace, ok := x.ExtractTrace(ctx); ok && len(trace.TraceID) != 0 {
+			return trace, true
+		}
+	}
+
+	return TraceContext{}, false
+}
+
+// TraceFromHeaders returns the trace found in the traceparent or
+// X-Amzn-Trace-Id headers of h, traceparent being used when both are valid.
+func TraceFromHeaders(h http.Header) (TraceContext, bool) {
+	if s := h.Get("traceparent"); len(s) != 0 {
+		if trace, err := ParseTraceparent(s); err == nil {
+			return trace, true
+		}
+	}
+
+	if s := h.Get("X-Amzn-Trace-Id"); len(s) != 0 {
+		if trace, err := ParseXRayTraceID(s); err == nil {
+			return trace, true
+		}
+	}
+
+	return TraceContext{}, false
+}
+
+// ParseTraceparent parses a W3C trace context traceparent header, of the form
+// "00-<trace-id>-<parent-id>-<flags>".
+func ParseTraceparent(s string) (trace TraceContext, err error) {
+	s = strings.TrimSpace(s)
+
+	// Versions above 00 may add fields after the flags.
+	if len(s) < 55 || (len(s) > 55 && s[55] != '-') || (s[:2] == "00" && len(s) != 55) {
+		err = ErrInvalidTraceparent
+		return
+	}
+
+	version, traceID, spanID, flags := s[:2], s[3:35], s[36:52], s[53:55]
+
+	if s[2] != '-' || s[35] != '-' || s[52] != '-' || version == "ff" ||
+		!isHex(version) || !isHex(traceID) || !isHex(spanID) || !isHex(flags) ||
+		isZeroHex(traceID) || isZeroHex(spanID) {
+		err = ErrInvalidTraceparent
+		return
+	}
+
+	trace = TraceContext{
+		TraceID: traceID,
+		SpanID:  spanID,
+		Sampled: (hexDigit(flags[1]) & 1) != 0,
+	}
+	return
+}
+
+// ParseXRayTraceID parses an AWS X-Ray X-Amzn-Trace-Id header, of the form
+// "Root=1-<time>-<id>;Parent=<id>;Sampled=1". The trace ID keeps the X-Ray
+// format so events can be looked up in the X-Ray console.
+func ParseXRayTraceID(s string) (trace TraceContext, err error) {
+	for _, field := range strings.Split(s, ";") {
+		i := strings.IndexByte(field, '=')
+		if i < 0 {
+			continue
+		}
+
+		switch k, v := strings.TrimSpace(field[:i]), strings.TrimSpace(field[i+1:]); k {
+		case "Root":
+			trace.TraceID = v
+		case "Parent":
+			trace.SpanID = v
+		case "Sampled":
+			trace.Sampled = v == "1"
+		}
+	}
+
+	if !isXRayTraceID(trace.TraceID) || (len(trace.SpanID) != 0 && (len(trace.SpanID) != 16 || !isHex(trace.SpanID))) {
+		trace, err = TraceContext{}, ErrInvalidXRayTraceID
+	}
+
+	return
+}
+
+func isXRayTraceID(s string) bool {
+	// 1-<8 hex digits>-<24 hex digits>
+	return len(s) == 35 && s[:2] == "1-" && s[10] == '-' && isHex(s[2:10]) && isHex(s[11:])
+}
+
+func isHex(s string) bool {
+	for i := 0; i != len(s); i++ {
+		if hexDigit(s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func isZeroHex(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+func hexDigit(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	default:
+		return -1
+	}
+}
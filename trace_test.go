@@ -0,0 +1,149 @@
+package ecslogs
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		header string
+		trace  TraceContext
+		err    error
+	}{
+		{
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			trace:  TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true},
+		},
+		{
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			trace:  TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"},
+		},
+		{
+			header: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future",
+			trace:  TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true},
+		},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", err: ErrInvalidTraceparent},
+		{header: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", err: ErrInvalidTraceparent},
+		{header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", err: ErrInvalidTraceparent},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", err: ErrInvalidTraceparent},
+		{header: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", err: ErrInvalidTraceparent},
+		{header: "00-4bf92f3577b34da6a3ce929d0e0e4736", err: ErrInvalidTraceparent},
+	}
+
+	for _, test := range tests {
+		t.Run(test.header, func(t *testing.T) {
+			trace, err := ParseTraceparent(test.header)
+
+			if err != test.err {
+				t.Error("invalid error:", err)
+			}
+
+			if trace != test.trace {
+				t.Errorf("invalid trace: %#v", trace)
+			}
+		})
+	}
+}
+
+func TestParseXRayTraceID(t *testing.T) {
+	tests := []struct {
+		header string
+		trace  TraceContext
+		err    error
+	}{
+		{
+			header: "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1",
+			trace:  TraceContext{TraceID: "1-5759e988-bd862e3fe1be46a994272793", SpanID: "53995c3f42cd8ad8", Sampled: true},
+		},
+		{
+			header: "Root=1-5759e988-bd862e3fe1be46a994272793",
+			trace:  TraceContext{TraceID: "1-5759e988-bd862e3fe1be46a994272793"},
+		},
+		{
+			header: "Self=1-67891234-12456789abcdef012345678;Root=1-5759e988-bd862e3fe1be46a994272793;Sampled=0",
+			trace:  TraceContext{TraceID: "1-5759e988-bd862e3fe1be46a994272793"},
+		},
+		{header: "Root=2-5759e988-bd862e3fe1be46a994272793", err: ErrInvalidXRayTraceID},
+		{header: "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=xyz", err: ErrInvalidXRayTraceID},
+		{header: "Parent=53995c3f42cd8ad8", err: ErrInvalidXRayTraceID},
+	}
+
+	for _, test := range tests {
+		t.Run(test.header, func(t *testing.T) {
+			trace, err := ParseXRayTraceID(test.header)
+
+			if err != test.err {
+				t.Error("invalid error:", err)
+			}
+
+			if trace != test.trace {
+				t.Errorf("invalid trace: %#v", trace)
+			}
+		})
+	}
+}
+
+func TestTraceFromHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-Amzn-Trace-Id", "Root=1-5759e988-bd862e3fe1be46a994272793")
+
+	if trace, ok := TraceFromHeaders(h); !ok || trace.TraceID != "1-5759e988-bd862e3fe1be46a994272793" {
+		t.Errorf("invalid trace: %#v", trace)
+	}
+
+	h.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	if trace, ok := TraceFromHeaders(h); !ok || trace.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("invalid trace: %#v", trace)
+	}
+
+	if _, ok := TraceFromHeaders(http.Header{}); ok {
+		t.Error("unexpected trace found in empty headers")
+	}
+}
+
+type spanKey struct{}
+
+func TestTraceExtractor(t *testing.T) {
+	RegisterTraceExtractor(TraceExtractorFunc(func(ctx context.Context) (TraceContext, bool) {
+		trace, ok := ctx.Value(spanKey{}).(TraceContext)
+		return trace, ok
+	}))
+
+	span := TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"}
+	ctx := context.WithValue(context.Background(), spanKey{}, span)
+
+	if trace, ok := TraceFromContext(ctx); !ok || trace != span {
+		t.Errorf("invalid trace: %#v", trace)
+	}
+
+	other := TraceContext{TraceID: "1-5759e988-bd862e3fe1be46a994272793"}
+
+	if trace, ok := TraceFromContext(ContextWithTrace(ctx, other)); !ok || trace != other {
+		t.Errorf("invalid trace: %#v", trace)
+	}
+
+	if _, ok := TraceFromContext(context.Background()); ok {
+		t.Error("unexpected trace found in an empty context")
+	}
+}
+
+func TestLogContextTrace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	ctx := ContextWithTrace(context.Background(), TraceContext{
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+	})
+
+	LoggerFromContext(ContextWithLogger(ctx, NewLogger(buf))).Log(Eprint(INFO, "hello"))
+
+	const expected = `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{},"data":{"spanId":"00f067aa0ba902b7","traceId":"4bf92f3577b34da6a3ce929d0e0e4736"},"message":"hello"}`
+
+	if s := strings.TrimSpace(buf.String()); s != expected {
+		t.Errorf("\n- expected: %s\n- found:    %s", expected, s)
+	}
+}
@@ -0,0 +1,85 @@
+package ecslogs
+
+import (
+	"context"
+	"sort"
+
+	"github.com/segmentio/encoding/json"
+)
+
+// With returns a logger that adds fields to the data of every event it logs,
+// fields set on events take precedence. When parent was created by
+// NewLoggerWith (directly or through other calls to With) the fields are
+// encoded once and reused for every event.
+func With(parent Logger, fields EventData) Logger {
+	if b, ok := parent.(*boundLogger); ok {
+		parent, fields = b.logger, copyEventData(b.fields.data, fields)
+	} else {
+		fields = copyEventData(fields)
+	}
+
+	b := &boundLogger{
+		logger: parent,
+		fields: &boundFields{data: fields},
+	}
+
+	if l, ok := parent.(*logger); ok {
+		b.fields.encoded = encodeBoundFields(fields, l.enc.flags)
+	}
+
+	return b
+}
+
+type boundLogger struct {
+	logger Logger
+	fields *boundFields
+}
+
+type boundFields struct {
+	data    EventData
+	encoded []boundField
+}
+
+type boundField struct {
+	key string
+	raw []byte // "key":value
+}
+
+func (b *boundLogger) Log(event Event) error {
+	if l, ok := b.logger.(*logger); ok && b.fields.encoded != nil {
+		return l.logBound(event, b.fields)
+	}
+	event.Data = copyEventData(b.fields.data, event.Data)
+	return b.logger.Log(event)
+}
+
+func (b *boundLogger) Flush(ctx context.Context) error {
+	if f, ok := b.logger.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// encodeBoundFields returns the fields encoded and sorted by key, or nil if
+// one of them could not be encoded.
+func encodeBoundFields(fields EventData, flags json.AppendFlags) []boundField {
+	encoded := make([]boundField, 0, len(fields))
+
+	for k, v := range fields {
+		b := appendString(nil, k, flags)
+		b = append(b, ':')
+		b, err := appendValue(b, v, flags)
+
+		if err != nil {
+			return nil
+		}
+
+		encoded = append(encoded, boundField{key: k, raw: b})
+	}
+
+	sort.Slice(encoded, func(i, j int) bool {
+		return encoded[i].key < encoded[j].key
+	})
+
+	return encoded
+}
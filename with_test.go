@@ -0,0 +1,128 @@
+package ecslogs
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestWith(t *testing.T) {
+	tests := []struct {
+		fields []EventData
+		event  Event
+	}{
+		{
+			fields: []EventData{{"service": "api"}},
+			event:  Eprint(INFO, "hello"),
+		},
+		{
+			fields: []EventData{{"a": 1, "c": 3, "e": 5}},
+			event:  Event{Level: INFO, Data: EventData{"b": 2, "c": "<c>", "d": 4, "f": 6}},
+		},
+		{
+			fields: []EventData{{"a": 1}, {"a": 2, "b": EventData{"c": []interface{}{1, "2"}}}},
+			event:  Event{Level: WARN, Data: nil},
+		},
+		{
+			fields: []EventData{{}},
+			event:  Event{Level: WARN, Data: nil},
+		},
+		{
+			fields: []EventData{{"ch": make(chan int)}},
+			event:  Eprint(INFO, "unserializable"),
+		},
+		{
+			fields: []EventData{{"a": 1}},
+			event:  Event{Level: INFO, Data: EventData{"ch": make(chan int)}},
+		},
+	}
+
+	for i, test := range tests {
+		b1 := &bytes.Buffer{}
+		b2 := &bytes.Buffer{}
+
+		log := NewLogger(b1)
+		data := EventData{}
+
+		for _, f := range test.fields {
+			log = With(log, f)
+			data = copyEventData(data, f)
+		}
+
+		e := test.event
+		log.Log(e)
+
+		e.Data = copyEventData(data, e.Data)
+		NewLogger(b2).Log(e)
+
+		if s1, s2 := b2.String(), b1.String(); s1 != s2 {
+			t.Errorf("test#%d:\n- expected: %s- found:    %s", i, s1, s2)
+		}
+	}
+}
+
+func TestWithLimits(t *testing.T) {
+	buf := &bytes.Buffer{}
+	log := With(NewLoggerWith(Config{Output: buf, Limits: Limits{MaxStringLen: 4}}), EventData{"a": "abcdefgh"})
+	log.Log(Eprint(INFO, ""))
+
+	const expected = `{"level":"INFO","time":"0001-01-01T00:00:00Z","info":{"truncated":["data.a"]},"data":{"a":"a..."},"message":""}
+`
+
+	if s := buf.String(); s != expected {
+		t.Errorf("\n- expected: %s- found:    %s", expected, s)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	var event Event
+
+	log := With(LoggerFunc(func(e Event) error {
+		event = e
+		return nil
+	}), EventData{"a": 1})
+
+	log.Log(Event{Data: EventData{"b": 2}})
+
+	if s := event.Data.String(); s != `{"a":1,"b":2}` {
+		t.Error("invalid event data:", s)
+	}
+}
+
+type countMarshaler struct {
+	n *int
+}
+
+func (m countMarshaler) MarshalJSON() ([]byte, error) {
+	*m.n++
+	return []byte(`"hello"`), nil
+}
+
+func TestWithPreEncoded(t *testing.T) {
+	n := 0
+	log := With(NewLogger(io.Discard), EventData{"value": countMarshaler{&n}})
+
+	for i := 0; i != 10; i++ {
+		log.Log(Eprint(INFO, ""))
+	}
+
+	if n != 1 {
+		t.Error("bound fields were encoded more than once:", n)
+	}
+}
+
+func BenchmarkWith(b *testing.B) {
+	log := With(NewLogger(io.Discard), EventData{
+		"service": "api",
+		"version": "1.2.3",
+		"region":  "us-west-2",
+		"nested":  EventData{"a": 1, "b": []interface{}{1, 2, 3}},
+	})
+
+	e := Eprint(INFO, "hello")
+	e.Data["hello"] = "world"
+
+	for i := 0; i != b.N; i++ {
+		log.Log(e)
+	}
+}